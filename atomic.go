@@ -0,0 +1,79 @@
+package bytefmt
+
+import "sync/atomic"
+
+// AtomicSize is a size which may be updated concurrently, such as a count of
+// memory or disk in use. The zero value is zero bytes. An AtomicSize must not be
+// copied after first use.
+//
+// Optional watermarks report when the size crosses a threshold:
+//
+//    used := &bytefmt.AtomicSize{
+//        Base:   bytefmt.Binary,
+//        High:   *bytefmt.New(900*bytefmt.MiB, bytefmt.Binary),
+//        OnHigh: func(s bytefmt.Size) { log.Printf("memory high: %v", s) },
+//        Low:    *bytefmt.New(500*bytefmt.MiB, bytefmt.Binary),
+//        OnLow:  func(s bytefmt.Size) { log.Printf("memory recovered: %v", s) },
+//    }
+//
+// Watermarks and their callbacks must be set before the size is used. Callbacks
+// are called synchronously by the goroutine which caused a crossing, and so may
+// be called concurrently by different goroutines.
+type AtomicSize struct {
+	n int64 // Accessed atomically; first for alignment on 32-bit platforms.
+
+	// Base determines how loaded sizes are formatted.
+	Base Base
+
+	// OnHigh, if set, is called with the new size each time it rises from below
+	// High to at least High.
+	High   Size
+	OnHigh func(Size)
+
+	// OnLow, if set, is called with the new size each time it falls from above
+	// Low to at most Low.
+	Low   Size
+	OnLow func(Size)
+}
+
+// Add atomically adds delta to the size and returns the new size.
+func (a *AtomicSize) Add(delta Size) Size {
+	n := atomic.AddInt64(&a.n, delta.bytes)
+	a.crossed(n-delta.bytes, n)
+	return a.size(n)
+}
+
+// Load atomically loads the size.
+func (a *AtomicSize) Load() Size { return a.size(atomic.LoadInt64(&a.n)) }
+
+// Store atomically stores s.
+func (a *AtomicSize) Store(s Size) { a.Swap(s) }
+
+// Swap atomically stores s and returns the previous size.
+func (a *AtomicSize) Swap(s Size) (old Size) {
+	n := atomic.SwapInt64(&a.n, s.bytes)
+	a.crossed(n, s.bytes)
+	return a.size(n)
+}
+
+// CompareAndSwap atomically stores new if the size equals old, returning whether
+// it did. Sizes are compared by count of bytes, without regard to their bases.
+func (a *AtomicSize) CompareAndSwap(old, new Size) bool {
+	if !atomic.CompareAndSwapInt64(&a.n, old.bytes, new.bytes) {
+		return false
+	}
+	a.crossed(old.bytes, new.bytes)
+	return true
+}
+
+func (a *AtomicSize) size(n int64) Size { return Size{bytes: n, Base: a.Base} }
+
+// crossed calls watermark callbacks for a change from old to new bytes.
+func (a *AtomicSize) crossed(old, new int64) {
+	if a.OnHigh != nil && old < a.High.bytes && new >= a.High.bytes {
+		a.OnHigh(a.size(new))
+	}
+	if a.OnLow != nil && old > a.Low.bytes && new <= a.Low.bytes {
+		a.OnLow(a.size(new))
+	}
+}
@@ -0,0 +1,77 @@
+package bytefmt
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestAtomicSize(t *testing.T) {
+	var a AtomicSize
+	a.Base = Binary
+	assertEqual(t, *New(0, Binary), a.Load(), "Zero value")
+
+	assertEqual(t, *New(3*KiB, Binary), a.Add(*New(3*KiB, Binary)), "Add")
+	assertEqual(t, *New(2*KiB, Binary), a.Add(*New(-KiB, Binary)), "Add negative")
+
+	a.Store(*New(MB, Metric))
+	assertEqual(t, *New(MB, Binary), a.Load(), "Load after store")
+
+	assertEqual(t, *New(MB, Binary), a.Swap(*New(5, Metric)), "Swap")
+	assertEqual(t, false, a.CompareAndSwap(*New(4, Binary), *New(6, Binary)), "Failed compare and swap")
+	assertEqual(t, true, a.CompareAndSwap(*New(5, Metric), *New(6, Binary)), "Compare and swap")
+	assertEqual(t, *New(6, Binary), a.Load(), "Load after compare and swap")
+}
+
+func TestAtomicSizeWatermarks(t *testing.T) {
+	var highs, lows []Size
+	a := &AtomicSize{
+		High:   *New(100, Metric),
+		OnHigh: func(s Size) { highs = append(highs, s) },
+		Low:    *New(50, Metric),
+		OnLow:  func(s Size) { lows = append(lows, s) },
+	}
+
+	a.Add(*New(99, Metric))
+	a.Add(*New(1, Metric))                                // Crosses high
+	a.Add(*New(10, Metric))                               // Remains high
+	a.Store(*New(60, Metric))                             // Between watermarks
+	a.Store(*New(100, Metric))                            // Crosses high again
+	a.CompareAndSwap(*New(100, Metric), *New(50, Metric)) // Crosses low
+	a.Add(*New(-10, Metric))                              // Remains low
+	a.Swap(*New(200, Metric))                             // Crosses high from low
+	a.Add(*New(-200, Metric))                             // Crosses low from high
+
+	assertEqual(t, []Size{*New(100, 0), *New(100, 0), *New(200, 0)}, highs, "High crossings")
+	assertEqual(t, []Size{*New(50, 0), *New(0, 0)}, lows, "Low crossings")
+}
+
+func TestAtomicSizeConcurrent(t *testing.T) {
+	var highs, lows int32
+	a := &AtomicSize{
+		High:   *New(16*KiB, Binary),
+		OnHigh: func(Size) { atomic.AddInt32(&highs, 1) },
+		Low:    *New(16*KiB-1, Binary),
+		OnLow:  func(Size) { atomic.AddInt32(&lows, 1) },
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 128; j++ {
+				a.Add(*New(KiB, Binary))
+			}
+			for j := 0; j < 128; j++ {
+				a.Add(*New(-KiB, Binary))
+			}
+		}()
+	}
+	wg.Wait()
+
+	// With adjacent watermarks, each rise above them is followed by a fall.
+	assertEqual(t, int64(0), a.Load().Int64(), "Final size")
+	assertEqual(t, true, highs > 0, "Crossed high watermark")
+	assertEqual(t, highs, lows, "Crossings")
+}
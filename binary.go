@@ -0,0 +1,100 @@
+package bytefmt
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// binaryVersion identifies the layout written by AppendBinary. Version 1 is:
+//
+//    version (1 byte) | base (1 byte) | byte count (zig-zag varint)
+//
+// Future layouts must use a new version so old data remains readable.
+const binaryVersion = 1
+
+// Base codes within the binary encoding. Bases are stored by code rather than
+// value so each fits in a single byte.
+const (
+	baseCodeUnset  = 0
+	baseCodeMetric = 1
+	baseCodeBinary = 2
+)
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (s Size) MarshalBinary() ([]byte, error) {
+	return s.AppendBinary(make([]byte, 0, 2+binary.MaxVarintLen64))
+}
+
+// AppendBinary appends the binary encoding of s to b and returns the extended
+// buffer. It implements the encoding.BinaryAppender interface.
+func (s Size) AppendBinary(b []byte) ([]byte, error) {
+	var code byte
+	switch s.Base {
+	case 0:
+		code = baseCodeUnset
+	case Metric:
+		code = baseCodeMetric
+	case Binary:
+		code = baseCodeBinary
+	default:
+		return nil, fmt.Errorf("can't encode bytefmt.Size: invalid base %d", s.Base)
+	}
+
+	var count [binary.MaxVarintLen64]byte
+	n := binary.PutVarint(count[:], s.bytes)
+
+	b = append(b, binaryVersion, code)
+	return append(b, count[:n]...), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (s *Size) UnmarshalBinary(data []byte) error {
+	size, err := decodeBinary(data)
+	if err != nil {
+		return fmt.Errorf("can't decode bytefmt.Size: %w", err)
+	}
+	*s = size
+	return nil
+}
+
+func decodeBinary(data []byte) (Size, error) {
+	if len(data) == 0 {
+		return Size{}, errors.New("empty data")
+	}
+	if data[0] != binaryVersion {
+		return Size{}, fmt.Errorf("unsupported version %d", data[0])
+	}
+	if len(data) < 2 {
+		return Size{}, errors.New("missing base")
+	}
+
+	var base Base
+	switch data[1] {
+	case baseCodeUnset:
+		base = 0
+	case baseCodeMetric:
+		base = Metric
+	case baseCodeBinary:
+		base = Binary
+	default:
+		return Size{}, fmt.Errorf("invalid base code %d", data[1])
+	}
+
+	bytes, n := binary.Varint(data[2:])
+	switch {
+	case n == 0:
+		return Size{}, errors.New("missing byte count")
+	case n < 0:
+		return Size{}, errors.New("byte count exceeds 64 bits")
+	case 2+n != len(data):
+		return Size{}, errors.New("unexpected trailing data")
+	}
+	return Size{bytes: bytes, Base: base}, nil
+}
+
+// GobEncode implements the gob.GobEncoder interface.
+func (s Size) GobEncode() ([]byte, error) { return s.MarshalBinary() }
+
+// GobDecode implements the gob.GobDecoder interface.
+func (s *Size) GobDecode(data []byte) error { return s.UnmarshalBinary(data) }
@@ -0,0 +1,92 @@
+package bytefmt
+
+import (
+	"bytes"
+	"encoding/gob"
+	"math"
+	"testing"
+)
+
+func TestMarshalBinary(t *testing.T) {
+	tests := []struct {
+		In     Size
+		Expect []byte
+	}{
+		{In: Size{}, Expect: []byte{1, 0, 0}},
+		{In: *New(0, Metric), Expect: []byte{1, 1, 0}},
+		{In: *New(-1, Metric), Expect: []byte{1, 1, 1}},
+		{In: *New(1, Binary), Expect: []byte{1, 2, 2}},
+		{In: *New(KiB, Binary), Expect: []byte{1, 2, 0x80, 0x10}},
+		{In: *New(math.MaxInt64, Metric),
+			Expect: []byte{1, 1, 0xfe, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}},
+		{In: *New(math.MinInt64, Binary),
+			Expect: []byte{1, 2, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}},
+	}
+
+	for _, test := range tests {
+		b, err := test.In.MarshalBinary()
+		if !assertNoErr(t, err, "Marshalling %v", test.In) {
+			continue
+		}
+		assertEqual(t, test.Expect, b, "Encoding of %v", test.In)
+
+		b, err = test.In.AppendBinary([]byte{0xaa})
+		if assertNoErr(t, err, "Appending %v", test.In) {
+			assertEqual(t, append([]byte{0xaa}, test.Expect...), b, "Appended %v", test.In)
+		}
+
+		var out Size
+		if assertNoErr(t, out.UnmarshalBinary(test.Expect), "Unmarshalling %v", test.Expect) {
+			assertEqual(t, test.In, out, "Decoding of %v", test.Expect)
+		}
+	}
+
+	_, err := Size{Base: 10}.MarshalBinary()
+	assertEqualErr(t, "can't encode bytefmt.Size: invalid base 10", err, "Invalid base")
+}
+
+func TestUnmarshalBinaryErrors(t *testing.T) {
+	tests := []struct {
+		In        []byte
+		ExpectErr string
+	}{
+		{In: nil, ExpectErr: "empty data"},
+		{In: []byte{2, 1, 0}, ExpectErr: "unsupported version 2"},
+		{In: []byte{1}, ExpectErr: "missing base"},
+		{In: []byte{1, 3, 0}, ExpectErr: "invalid base code 3"},
+		{In: []byte{1, 1}, ExpectErr: "missing byte count"},
+		{In: []byte{1, 1, 0x80}, ExpectErr: "missing byte count"},
+		{In: []byte{1, 1, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01},
+			ExpectErr: "byte count exceeds 64 bits"},
+		{In: []byte{1, 1, 0, 0}, ExpectErr: "unexpected trailing data"},
+	}
+
+	for _, test := range tests {
+		var out Size
+		err := out.UnmarshalBinary(test.In)
+		assertEqualErr(t, "can't decode bytefmt.Size: "+test.ExpectErr, err, "Decoding %v", test.In)
+	}
+}
+
+func TestGob(t *testing.T) {
+	type snapshot struct {
+		Cache Size
+		Quota NullSize
+		Spill NullSize
+	}
+
+	in := snapshot{
+		Cache: *New(512*MiB, Binary),
+		Quota: NullSizeFrom(*New(0, Metric)),
+	}
+
+	var buf bytes.Buffer
+	if !assertNoErr(t, gob.NewEncoder(&buf).Encode(in), "Encoding %+v", in) {
+		return
+	}
+
+	var out snapshot
+	if assertNoErr(t, gob.NewDecoder(&buf).Decode(&out), "Decoding %+v", in) {
+		assertEqual(t, in, out, "Round trip")
+	}
+}
@@ -0,0 +1,156 @@
+package bytefmt
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Budget limits the total size of resources held at once, such as memory
+// buffered by concurrent requests. Callers acquire a share of the budget before
+// using resources and release it when done.
+//
+// Waiting callers are admitted in the order they arrived, so a large request
+// isn't starved by a stream of smaller ones. Budget is safe for concurrent use.
+type Budget struct {
+	mu      sync.Mutex
+	total   Size
+	used    int64
+	peak    int64
+	waiters list.List // Of *budgetWaiter, in arrival order
+}
+
+type budgetWaiter struct {
+	n     int64
+	ready chan struct{} // Closed once the share is acquired
+}
+
+// NewBudget returns a budget of the given total size. Reported sizes use the
+// total's base.
+func NewBudget(total Size) *Budget {
+	return &Budget{total: total}
+}
+
+// Acquire blocks until a share of n bytes is acquired or ctx is done. It returns
+// an error without waiting if n is negative, a *LimitExceededError if n exceeds
+// the whole budget, or the context's error if it's done first, in which case
+// nothing is acquired.
+func (b *Budget) Acquire(ctx context.Context, n Size) error {
+	if n.bytes < 0 {
+		return fmt.Errorf("can't acquire a negative share of %s", n.String())
+	}
+
+	b.mu.Lock()
+	if n.bytes > b.total.bytes {
+		b.mu.Unlock()
+		return limitExceeded(b.total, n.bytes)
+	}
+	if b.waiters.Len() == 0 && b.used+n.bytes <= b.total.bytes {
+		b.acquire(n.bytes)
+		b.mu.Unlock()
+		return nil
+	}
+
+	w := &budgetWaiter{n: n.bytes, ready: make(chan struct{})}
+	elem := b.waiters.PushBack(w)
+	b.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		b.mu.Lock()
+		select {
+		case <-w.ready:
+			// The share was acquired after the context was done. Release it, since
+			// the caller will see an error.
+			b.used -= w.n
+		default:
+			b.waiters.Remove(elem)
+		}
+		// Removing a waiter or releasing its share may admit those behind it.
+		b.notify()
+		b.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// TryAcquire acquires a share of n bytes without blocking, returning whether it
+// succeeded. It fails if any callers are waiting or n is negative.
+func (b *Budget) TryAcquire(n Size) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if n.bytes < 0 || b.waiters.Len() != 0 || b.used+n.bytes > b.total.bytes {
+		return false
+	}
+	b.acquire(n.bytes)
+	return true
+}
+
+// Release returns a share of n bytes to the budget, admitting waiting callers
+// for which there is now room. It panics if n is negative or more is released
+// than was acquired.
+func (b *Budget) Release(n Size) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if n.bytes < 0 {
+		panic("bytefmt: released a negative share of budget")
+	}
+	if n.bytes > b.used {
+		panic("bytefmt: released more than was acquired from budget")
+	}
+	b.used -= n.bytes
+	b.notify()
+}
+
+// Total returns the size of the whole budget.
+func (b *Budget) Total() Size { return b.total }
+
+// Used returns the size currently acquired.
+func (b *Budget) Used() Size {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return Size{bytes: b.used, Base: b.total.Base}
+}
+
+// Peak returns the most ever acquired at once.
+func (b *Budget) Peak() Size {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return Size{bytes: b.peak, Base: b.total.Base}
+}
+
+// ResetPeak sets the peak to the size currently acquired, returning the previous
+// peak.
+func (b *Budget) ResetPeak() Size {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	peak := b.peak
+	b.peak = b.used
+	return Size{bytes: peak, Base: b.total.Base}
+}
+
+func (b *Budget) acquire(n int64) {
+	b.used += n
+	if b.used > b.peak {
+		b.peak = b.used
+	}
+}
+
+// notify admits waiters in order until one doesn't fit.
+func (b *Budget) notify() {
+	for {
+		front := b.waiters.Front()
+		if front == nil {
+			return
+		}
+		w := front.Value.(*budgetWaiter)
+		if b.used+w.n > b.total.bytes {
+			return
+		}
+		b.acquire(w.n)
+		b.waiters.Remove(front)
+		close(w.ready)
+	}
+}
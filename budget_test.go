@@ -0,0 +1,115 @@
+package bytefmt
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBudget(t *testing.T) {
+	b := NewBudget(*New(2*GiB, Binary))
+	ctx := context.Background()
+
+	assertNoErr(t, b.Acquire(ctx, *New(GiB, Binary)), "Acquiring")
+	assertEqual(t, true, b.TryAcquire(*New(512*MiB, Binary)), "Trying to acquire within budget")
+	assertEqual(t, false, b.TryAcquire(*New(768*MiB, Binary)), "Trying to acquire over budget")
+	assertEqual(t, *New(1536*MiB, Binary), b.Used(), "Used")
+
+	b.Release(*New(GiB, Binary))
+	assertEqual(t, *New(512*MiB, Binary), b.Used(), "Used after release")
+	assertEqual(t, *New(1536*MiB, Binary), b.Peak(), "Peak")
+	assertEqual(t, *New(1536*MiB, Binary), b.ResetPeak(), "Peak before reset")
+	assertEqual(t, *New(512*MiB, Binary), b.Peak(), "Peak after reset")
+	assertEqual(t, *New(2*GiB, Binary), b.Total(), "Total")
+
+	// Requests larger than the whole budget fail immediately.
+	err := b.Acquire(ctx, *New(3*GB, Metric))
+	assertEqualErr(t, "3000000000 B exceeds the limit of 2 GiB", err, "Acquiring more than the budget")
+	var limitErr *LimitExceededError
+	if assertEqual(t, true, errors.As(err, &limitErr), "Error type") {
+		assertEqual(t, *New(3*GB, Binary), limitErr.Attempted, "Attempted")
+	}
+
+	// Negative shares are rejected.
+	err = b.Acquire(ctx, *New(-MiB, Binary))
+	assertEqualErr(t, "can't acquire a negative share of -1 MiB", err, "Acquiring a negative share")
+	assertEqual(t, false, b.TryAcquire(*New(-MiB, Binary)), "Trying to acquire a negative share")
+	assertEqual(t, *New(512*MiB, Binary), b.Used(), "Used after negative shares")
+	func() {
+		defer func() {
+			assertEqual(t, "bytefmt: released a negative share of budget", recover(), "Panic")
+		}()
+		b.Release(*New(-MiB, Binary))
+	}()
+
+	defer func() {
+		assertEqual(t, "bytefmt: released more than was acquired from budget", recover(), "Panic")
+	}()
+	b.Release(*New(GiB, Binary))
+}
+
+func TestBudgetFIFO(t *testing.T) {
+	b := NewBudget(*New(10, Metric))
+	ctx := context.Background()
+	assertNoErr(t, b.Acquire(ctx, *New(8, Metric)), "Acquiring")
+
+	// A large waiter at the front blocks smaller requests which would fit.
+	var mu sync.Mutex
+	var order []int64
+	var wg sync.WaitGroup
+	for i, n := range []int64{10, 1, 2} {
+		wg.Add(1)
+		go func(n int64) {
+			defer wg.Done()
+			assertNoErr(t, b.Acquire(ctx, *New(n, Metric)), "Acquiring %d", n)
+			mu.Lock()
+			order = append(order, n)
+			mu.Unlock()
+			b.Release(*New(n, Metric))
+		}(n)
+		waitForWaiters(b, i+1)
+	}
+
+	assertEqual(t, false, b.TryAcquire(*New(1, Metric)), "Trying to acquire while others wait")
+	b.Release(*New(8, Metric))
+	wg.Wait()
+
+	assertEqual(t, 10, int(order[0]), "First admitted")
+	assertEqual(t, *New(0, Metric), b.Used(), "Used after all released")
+	assertEqual(t, *New(10, Metric), b.Peak(), "Peak")
+}
+
+func TestBudgetCancel(t *testing.T) {
+	b := NewBudget(*New(10, Metric))
+	assertNoErr(t, b.Acquire(context.Background(), *New(8, Metric)), "Acquiring")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- b.Acquire(ctx, *New(5, Metric)) }()
+	waitForWaiters(b, 1)
+
+	// A small request waits behind the large one until it's cancelled.
+	small := make(chan error)
+	go func() { small <- b.Acquire(context.Background(), *New(2, Metric)) }()
+	waitForWaiters(b, 2)
+
+	cancel()
+	assertEqualErr(t, "context canceled", <-done, "Cancelled acquire")
+	assertNoErr(t, <-small, "Acquiring after cancellation")
+	assertEqual(t, *New(10, Metric), b.Used(), "Used after cancellation")
+}
+
+// waitForWaiters blocks until at least n callers are waiting on a budget.
+func waitForWaiters(b *Budget, n int) {
+	for {
+		b.mu.Lock()
+		waiting := b.waiters.Len()
+		b.mu.Unlock()
+		if waiting >= n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -7,18 +7,16 @@ import (
 	"database/sql/driver"
 	"errors"
 	"fmt"
+	"io"
 	"math"
 	"math/big"
+	"math/bits"
 	"strconv"
 	"strings"
 )
 
 // Commonly used values; do not change.
-var (
-	ten      = big.NewInt(10)
-	tenPow3  = big.NewInt(1000)
-	twoPow10 = big.NewInt(1024)
-)
+var ten = big.NewInt(10)
 
 // New returns a new size from a count of bytes.
 func New(bytes int64, base Base) *Size {
@@ -97,7 +95,123 @@ func Parse(s string) (*Size, error) {
 	return size, nil
 }
 
+// ParseBytes is like Parse, but operates on a byte slice and returns a value. It
+// doesn't allocate for most valid inputs, making it suitable for hot paths such
+// as decoding large volumes of records.
+func ParseBytes(b []byte) (Size, error) {
+	if size, ok := parseFast(b); ok {
+		return size, nil
+	}
+	size, err := parseBig(string(b))
+	if err != nil {
+		return Size{}, fmt.Errorf("can't convert %q to size: %w", b, err)
+	}
+	return *size, nil
+}
+
+// Powers of ten representable by uint64, indexed by exponent.
+var pow10 = [...]uint64{
+	1e0, 1e1, 1e2, 1e3, 1e4, 1e5, 1e6, 1e7, 1e8, 1e9,
+	1e10, 1e11, 1e12, 1e13, 1e14, 1e15, 1e16, 1e17, 1e18, 1e19,
+}
+
+// parseFast parses a size using only 64-bit arithmetic. It returns false if the
+// input is invalid or needs greater precision, in which case it must be parsed
+// with parse instead.
+func parseFast(b []byte) (Size, bool) {
+	pos, end := 0, len(b)
+
+	var negative bool
+	if pos < end && b[pos] == '-' {
+		negative = true
+		pos++
+	}
+
+	start := pos
+	var whole uint64
+	for ; pos < end && b[pos] >= '0' && b[pos] <= '9'; pos++ {
+		if whole > (math.MaxUint64-9)/10 {
+			return Size{}, false
+		}
+		whole = whole*10 + uint64(b[pos]-'0')
+	}
+	digits := pos - start
+
+	var frac uint64
+	var fracDigits int
+	if pos < end && b[pos] == '.' {
+		for pos++; pos < end && b[pos] >= '0' && b[pos] <= '9'; pos++ {
+			if fracDigits == len(pow10)-1 {
+				return Size{}, false
+			}
+			frac = frac*10 + uint64(b[pos]-'0')
+			fracDigits++
+			digits++
+		}
+	}
+	if digits == 0 {
+		return Size{}, false
+	}
+	for fracDigits != 0 && frac%10 == 0 {
+		frac /= 10
+		fracDigits--
+	}
+
+	if pos < end && b[pos] == ' ' {
+		pos++
+	}
+	exp, base, ok := lookupSuffix(b[pos:])
+	if !ok {
+		return Size{}, false
+	}
+	scale := uint64(1)
+	for i := 0; i < exp; i++ {
+		scale *= uint64(base)
+	}
+
+	// value = (whole * 10**fracDigits + frac) * scale / 10**fracDigits
+	prec := pow10[fracDigits]
+	hi, val := bits.Mul64(whole, prec)
+	if hi != 0 {
+		return Size{}, false
+	}
+	val, carry := bits.Add64(val, frac, 0)
+	if carry != 0 {
+		return Size{}, false
+	}
+	hi, val = bits.Mul64(val, scale)
+	if hi >= prec {
+		return Size{}, false // The quotient would overflow.
+	}
+	val, _ = bits.Div64(hi, val, prec)
+
+	if negative {
+		if val > 1<<63 {
+			return Size{}, false
+		}
+		return Size{bytes: -int64(val), Base: base}, true
+	}
+	if val > math.MaxInt64 {
+		return Size{}, false
+	}
+	return Size{bytes: int64(val), Base: base}, true
+}
+
 func parse(s string) (*Size, error) {
+	if size, ok := parseFast([]byte(s)); ok {
+		return &size, nil
+	}
+	return parseBig(s)
+}
+
+// parseBig parses a size using arbitrary precision.
+func parseBig(s string) (*Size, error) {
+	return parseUnit(s, builtinUnit)
+}
+
+// parseUnit parses a size using arbitrary precision, where lookup resolves the
+// unit suffix. Fractional bytes are truncated.
+func parseUnit(s string, lookup func(suffix string) (Unit, error)) (*Size, error) {
 	if len(s) == 0 {
 		return nil, errors.New("empty string")
 	}
@@ -149,7 +263,7 @@ func parse(s string) (*Size, error) {
 	}
 
 	// Everything remaining must be the unit suffix.
-	exp, base, err := parseSuffix(s[pos:end])
+	unit, err := lookup(s[pos:end])
 	if err != nil {
 		return nil, err
 	}
@@ -159,15 +273,7 @@ func parse(s string) (*Size, error) {
 
 	var val, scale big.Int
 	val.SetString(whole, 10)
-
-	// Calculate the scalar. Base is guaranteed valid by parseSuffix.
-	scale.SetInt64(int64(exp))
-	switch base {
-	case Metric:
-		scale.Exp(tenPow3, &scale, nil)
-	case Binary:
-		scale.Exp(twoPow10, &scale, nil)
-	}
+	scale.SetInt64(unit.Bytes)
 
 	// Scale the number.
 	if len(frac) != 0 {
@@ -188,37 +294,46 @@ func parse(s string) (*Size, error) {
 		return nil, errors.New("value exceeds 64 bits")
 	}
 
-	return &Size{bytes: val.Int64(), Base: base}, nil
+	return &Size{bytes: val.Int64(), Base: unit.Base}, nil
 }
 
 // String returns the formatted quantity scaled to the largest exact base unit.
 func (s Size) String() string {
+	result := make([]byte, 0, 20) // Pre-allocate a size most numbers would fit within.
+	return string(s.appendString(result))
+}
+
+// AppendText appends the result of String to b and returns the extended buffer.
+// It implements the encoding.TextAppender interface.
+func (s Size) AppendText(b []byte) ([]byte, error) {
+	return s.appendString(b), nil
+}
+
+func (s Size) appendString(b []byte) []byte {
 	mant := s.bytes
 	var exp int
 	var suffix string
 
 	switch s.Base {
 	case 0, Metric:
-		for mant != 0 && mant%1000 == 0 && exp < len(metricSuffixes) {
+		for mant != 0 && mant%1000 == 0 && exp < len(metricUnits) {
 			exp++
 			mant = mant / 1000
 		}
-		suffix = metricSuffixes[exp]
+		suffix = metricUnits[exp].Symbol
 	case Binary:
-		for mant != 0 && mant%1024 == 0 && exp < len(binarySuffixes) {
+		for mant != 0 && mant%1024 == 0 && exp < len(binaryUnits) {
 			exp++
 			mant = mant / 1024
 		}
-		suffix = binarySuffixes[exp]
+		suffix = binaryUnits[exp].Symbol
 	default:
 		panic("invalid base")
 	}
 
-	result := make([]byte, 0, 20) // Pre-allocate a size most numbers would fit within.
-	result = strconv.AppendInt(result, mant, 10)
-	result = append(result, ' ')
-	result = append(result, suffix...)
-	return string(result)
+	b = strconv.AppendInt(b, mant, 10)
+	b = append(b, ' ')
+	return append(b, suffix...)
 }
 
 // Format implements the fmt.Formatter interface.
@@ -229,7 +344,7 @@ func (s Size) String() string {
 //  - 'v': Equivalent to '%.4g'.
 //
 // Setting width is not supported.
-// Flags are also not supported.
+// Flags are also not supported, except that '%#v' formats the result of GoString.
 //
 // The largest base unit smaller than the quantity is used.
 // For example, 999 bytes is formatted as "999 B" and 1000 bytes is formatted as "1 kB".
@@ -244,6 +359,10 @@ func (s Size) Format(f fmt.State, verb rune) {
 		format = 'g'
 		precision = -1
 	case 'v':
+		if f.Flag('#') {
+			io.WriteString(f, s.GoString())
+			return
+		}
 		format = 'g'
 		precision = 4
 	default:
@@ -254,15 +373,23 @@ func (s Size) Format(f fmt.State, verb rune) {
 		precision = prec
 	}
 
+	result := make([]byte, 0, 20) // Pre-allocate a size most numbers would fit within.
+	f.Write(s.AppendFormat(result, format, precision))
+}
+
+// AppendFormat appends the size, formatted as by Format, to dst and returns the
+// extended buffer. The format is 'f' or 'g', and prec is the precision as in
+// strconv.FormatFloat, where -1 uses the fewest digits necessary.
+func (s Size) AppendFormat(dst []byte, format byte, prec int) []byte {
 	var base float64
-	var suffixes [7]string
+	var units *[7]Unit
 	switch s.Base {
 	case 0, Metric:
 		base = 1000
-		suffixes = metricSuffixes
+		units = &metricUnits
 	case Binary:
 		base = 1024
-		suffixes = binarySuffixes
+		units = &binaryUnits
 	default:
 		panic("invalid base")
 	}
@@ -271,15 +398,13 @@ func (s Size) Format(f fmt.State, verb rune) {
 	var exp float64
 	if mant != 0 {
 		exp = math.Floor(math.Log(math.Abs(mant)) / math.Log(base))
-		exp = math.Min(exp, float64(len(suffixes)))
+		exp = math.Min(exp, float64(len(units)))
 	}
 	mant = mant / math.Pow(base, exp)
 
-	result := make([]byte, 0, 20) // Pre-allocate a size most numbers would fit within.
-	result = strconv.AppendFloat(result, mant, format, precision, 64)
-	result = append(result, ' ')
-	result = append(result, suffixes[int(exp)]...)
-	f.Write(result)
+	dst = strconv.AppendFloat(dst, mant, format, prec, 64)
+	dst = append(dst, ' ')
+	return append(dst, units[int(exp)].Symbol...)
 }
 
 // MarshalText implements the encoding.TextMarshaler interface.
@@ -301,26 +426,39 @@ func (s Size) MarshalJSON() ([]byte, error) {
 	return []byte(strconv.Quote(s.String())), nil
 }
 
-// UnmarshalJSON implements the json.Unmarshaler interface.
+// UnmarshalJSON implements the json.Unmarshaler interface. It accepts strings,
+// numbers, which are interpreted as a count of bytes, and objects in the form
+// produced by DisplaySize.
 func (s *Size) UnmarshalJSON(value []byte) error {
 	if string(value) == "null" {
 		return errors.New("can't decode null as bytefmt.Size")
 	}
 
-	// Strip quotes if present.
-	str := string(value)
-	if len(str) > 2 && str[0] == '"' {
-		var err error
-		if str, err = strconv.Unquote(str); err != nil {
-			return fmt.Errorf("can't decode %q as bytefmt.Size: %w", value, err)
+	if len(value) != 0 {
+		switch value[0] {
+		case '"':
+			str, err := strconv.Unquote(string(value))
+			if err != nil {
+				return fmt.Errorf("can't decode %q as bytefmt.Size: %w", value, err)
+			}
+			size, err := Parse(str)
+			if size != nil {
+				*s = *size
+			}
+			return err
+
+		case '{':
+			return s.unmarshalJSONObject(value)
 		}
 	}
 
-	size, err := Parse(str)
-	if size != nil {
-		*s = *size
+	// Anything else must be a number.
+	bytes, err := parseJSONNumber(value)
+	if err != nil {
+		return fmt.Errorf("can't decode %q as bytefmt.Size: %w", value, err)
 	}
-	return err
+	*s = *New(bytes, Metric)
+	return nil
 }
 
 // Value implements the sql.Valuer interface. It always produces a string.
@@ -329,28 +467,97 @@ func (s Size) Value() (driver.Value, error) {
 }
 
 // Scan implements the sql.Scanner interface. It accepts numeric and string values.
+// Numbers, including numeric strings, must hold a whole number of bytes.
 func (s *Size) Scan(value interface{}) error {
 	switch v := value.(type) {
-	case int64:
-		*s = *New(v, Metric)
-		return nil
-
 	case string:
-		size, err := Parse(v)
-		if size != nil {
-			*s = *size
-		}
-		return err
+		return s.scanText([]byte(v))
 
 	case []byte:
-		size, err := Parse(string(v))
-		if size != nil {
-			*s = *size
-		}
-		return err
+		return s.scanText(v)
+	}
 
-	default: // Interpret as a string.
+	bytes, ok, err := fromNumber(value)
+	switch {
+	case !ok:
 		return fmt.Errorf("could not convert value '%+v' of type '%T' to bytefmt.Size", value, value)
+	case err != nil:
+		return fmt.Errorf("could not convert value '%+v' of type '%T' to bytefmt.Size: %w",
+			value, value, err)
+	}
+	*s = *New(bytes, Metric)
+	return nil
+}
+
+func (s *Size) scanText(value []byte) error {
+	// Plain numbers, such as those from DECIMAL columns, are held to the same
+	// exactness as numeric types. Anything else is parsed normally.
+	if isJSONNumber(value) {
+		bytes, err := parseJSONNumber(value)
+		if err != nil {
+			return fmt.Errorf("could not convert value %q to bytefmt.Size: %w", value, err)
+		}
+		*s = *New(bytes, Metric)
+		return nil
+	}
+
+	size, err := Parse(string(value))
+	if size != nil {
+		*s = *size
+	}
+	return err
+}
+
+// fromNumber converts a numeric value to a count of bytes. Floating point values
+// must be whole numbers. It returns false if the value isn't numeric.
+func fromNumber(value interface{}) (int64, bool, error) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), true, nil
+	case int8:
+		return int64(v), true, nil
+	case int16:
+		return int64(v), true, nil
+	case int32:
+		return int64(v), true, nil
+	case int64:
+		return v, true, nil
+	case uint:
+		return fromUint64(uint64(v))
+	case uint8:
+		return int64(v), true, nil
+	case uint16:
+		return int64(v), true, nil
+	case uint32:
+		return int64(v), true, nil
+	case uint64:
+		return fromUint64(v)
+	case float32:
+		return fromFloat64(float64(v))
+	case float64:
+		return fromFloat64(v)
+	default:
+		return 0, false, nil
+	}
+}
+
+func fromUint64(v uint64) (int64, bool, error) {
+	if v > math.MaxInt64 {
+		return 0, true, errors.New("value exceeds 64 bits")
+	}
+	return int64(v), true, nil
+}
+
+func fromFloat64(v float64) (int64, bool, error) {
+	switch {
+	case math.IsNaN(v) || math.IsInf(v, 0):
+		return 0, true, fmt.Errorf("%v is not a number of bytes", v)
+	case v != math.Trunc(v):
+		return 0, true, fmt.Errorf("%v is not a whole number of bytes", v)
+	case v < math.MinInt64 || v >= math.MaxInt64: // MaxInt64 rounds up to 2**63.
+		return 0, true, errors.New("value exceeds 64 bits")
+	default:
+		return int64(v), true, nil
 	}
 }
 
@@ -360,6 +567,103 @@ type NullSize struct {
 	Valid bool
 }
 
+// NullSizeFrom returns a valid NullSize holding s.
+func NullSizeFrom(s Size) NullSize { return NullSize{Size: s, Valid: true} }
+
+// ParseNull converts a string representation of a byte quantity to a NullSize.
+// An empty string is null; anything else is parsed as by Parse.
+func ParseNull(s string) (NullSize, error) {
+	if s == "" {
+		return NullSize{}, nil
+	}
+	size, err := Parse(s)
+	if err != nil {
+		return NullSize{}, err
+	}
+	return NullSizeFrom(*size), nil
+}
+
+// Equal returns whether two sizes are both null or represent the same number of
+// bytes.
+func (s NullSize) Equal(y NullSize) bool { return s.Cmp(y) == 0 }
+
+// Cmp compares s and y and returns:
+//   -1 if s <  y
+//    0 if s == y
+//   +1 if s >  y
+//
+// Null is less than all other sizes and equal only to itself.
+func (s NullSize) Cmp(y NullSize) int {
+	switch {
+	case !s.Valid && !y.Valid:
+		return 0
+	case !s.Valid:
+		return -1
+	case !y.Valid:
+		return 1
+	default:
+		return s.Size.Cmp(y.Size)
+	}
+}
+
+// Add adds size y to the current value. As in SQL, the result is null if either
+// operand is null.
+func (s *NullSize) Add(y NullSize) {
+	if !s.Valid || !y.Valid {
+		*s = NullSize{}
+		return
+	}
+	s.Size.Add(y.Size)
+}
+
+// Sub subtracts size y from the current value. As in SQL, the result is null if
+// either operand is null.
+func (s *NullSize) Sub(y NullSize) {
+	if !s.Valid || !y.Valid {
+		*s = NullSize{}
+		return
+	}
+	s.Size.Sub(y.Size)
+}
+
+// String returns the formatted size, or an empty string if null.
+func (s NullSize) String() string {
+	if !s.Valid {
+		return ""
+	}
+	return s.Size.String()
+}
+
+// Format implements the fmt.Formatter interface. Valid sizes are formatted as by
+// Size.Format; null sizes produce no output, except that '%#v' formats the result
+// of GoString.
+func (s NullSize) Format(f fmt.State, verb rune) {
+	if verb == 'v' && f.Flag('#') {
+		io.WriteString(f, s.GoString())
+		return
+	}
+	if s.Valid {
+		s.Size.Format(f, verb)
+	}
+}
+
+// MarshalText implements the encoding.TextMarshaler interface. A null size
+// produces empty text.
+func (s NullSize) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface. Empty text
+// sets the size to null.
+func (s *NullSize) UnmarshalText(value []byte) error {
+	size, err := ParseNull(string(value))
+	if err != nil {
+		return err
+	}
+	*s = size
+	return nil
+}
+
 // Value implements the driver.Valuer interface.
 func (s NullSize) Value() (driver.Value, error) {
 	if !s.Valid {
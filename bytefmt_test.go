@@ -132,6 +132,54 @@ func TestParse(t *testing.T) {
 	}
 }
 
+func TestParseBytes(t *testing.T) {
+	tests := []string{
+		"", "-", ".", "1.", ".1", "-.1", "1 ", "1  B", "1 tUb",
+		"0", "-0", "1", "999", "1024k", "1.1gb", "1.25 GiB", "123.456 GiB",
+		"0.000000000000000001 EB", "0.0000000000000000001 EB", "1.00000000000000000000 kB",
+		"18446744073709551615", "18446744073709551616", "99999999999999999999999",
+		"9223372036854775807", "9223372036854775808", "-9223372036854775808",
+		"-9223372036854775809", "8 EiB", "-8 EiB", "7.99999999999999999914 EiB",
+		"9.223372036854775807eb", "9.223372036854775808eb", "-9.223372036854775808eb",
+		"18.446744073709551615 EB", "1.5 B", "-1.5 B", "0.9999 kB", "-0.9999 KiB",
+	}
+
+	// The fast path must agree with arbitrary precision wherever it applies.
+	for _, in := range tests {
+		expect, expectErr := parseBig(in)
+		if fast, ok := parseFast([]byte(in)); ok {
+			if assertNoErr(t, expectErr, "Fast path accepted %q", in) {
+				assertEqual(t, *expect, fast, "Fast path for %q", in)
+			}
+		}
+
+		size, err := ParseBytes([]byte(in))
+		if expectErr != nil {
+			expectErr = fmt.Errorf("can't convert %q to size: %w", in, expectErr)
+			assertEqualErr(t, expectErr.Error(), err, "Error for %q", in)
+			continue
+		}
+		if assertNoErr(t, err, "Unexpected error for %q", in) {
+			assertEqual(t, *expect, size, "Size for %q", in)
+		}
+	}
+}
+
+func TestAllocations(t *testing.T) {
+	in := []byte("123.456 GiB")
+	size := *New(123*GiB, Binary)
+	buf := make([]byte, 0, 32)
+
+	allocs := testing.AllocsPerRun(100, func() { _, _ = ParseBytes(in) })
+	assertEqual(t, 0.0, allocs, "Allocations by ParseBytes")
+
+	allocs = testing.AllocsPerRun(100, func() { _, _ = size.AppendText(buf[:0]) })
+	assertEqual(t, 0.0, allocs, "Allocations by AppendText")
+
+	allocs = testing.AllocsPerRun(100, func() { _ = size.AppendFormat(buf[:0], 'g', 4) })
+	assertEqual(t, 0.0, allocs, "Allocations by AppendFormat")
+}
+
 func TestString(t *testing.T) {
 	tests := []struct {
 		In     *Size
@@ -335,6 +383,158 @@ func TestFormat(t *testing.T) {
 	}
 }
 
+func TestScan(t *testing.T) {
+	tests := []struct {
+		In        interface{}
+		Expect    *Size
+		ExpectErr string
+	}{
+		// Strings are parsed.
+		{In: "1.5 GiB", Expect: New(1536*MiB, Binary)},
+		{In: []byte("64k"), Expect: New(64*KB, Metric)},
+		{In: "", ExpectErr: `can't convert "" to size: empty string`},
+
+		// Numeric strings must be exact.
+		{In: []byte("1048576"), Expect: New(MiB, Metric)},
+		{In: []byte("1048576.000"), Expect: New(MiB, Metric)},
+		{In: "1.5", ExpectErr: `could not convert value "1.5" to bytefmt.Size: 1.5 is not a whole number of bytes`},
+
+		// Integers of any width
+		{In: int64(-1), Expect: New(-1, Metric)},
+		{In: int32(1024), Expect: New(1024, Metric)},
+		{In: uint64(math.MaxInt64), Expect: New(math.MaxInt64, Metric)},
+		{In: uint64(math.MaxUint64), ExpectErr: "could not convert value '18446744073709551615' " +
+			"of type 'uint64' to bytefmt.Size: value exceeds 64 bits"},
+
+		// Floats must be exact.
+		{In: float64(1e9), Expect: New(GB, Metric)},
+		{In: float64(-2048), Expect: New(-2048, Metric)},
+		{In: float64(0.5), ExpectErr: "could not convert value '0.5' of type 'float64' " +
+			"to bytefmt.Size: 0.5 is not a whole number of bytes"},
+		{In: math.NaN(), ExpectErr: "could not convert value 'NaN' of type 'float64' " +
+			"to bytefmt.Size: NaN is not a number of bytes"},
+		{In: float64(math.MaxInt64), ExpectErr: "could not convert value '9.223372036854776e+18' " +
+			"of type 'float64' to bytefmt.Size: value exceeds 64 bits"},
+
+		// Other types
+		{In: true, ExpectErr: "could not convert value 'true' of type 'bool' to bytefmt.Size"},
+	}
+
+	for _, test := range tests {
+		var size Size
+		err := size.Scan(test.In)
+
+		if test.ExpectErr != "" {
+			assertEqualErr(t, test.ExpectErr, err, "Error for %v", test.In)
+			continue
+		}
+		if assertNoErr(t, err, "Unexpected error for %v", test.In) {
+			assertEqual(t, *test.Expect, size, "Size for %v", test.In)
+		}
+
+		var null NullSize
+		if assertNoErr(t, null.Scan(test.In), "Unexpected error for %v", test.In) {
+			assertEqual(t, NullSize{Size: *test.Expect, Valid: true}, null, "NullSize for %v", test.In)
+		}
+	}
+}
+
+func TestNullSize(t *testing.T) {
+	null := NullSize{}
+	small := NullSizeFrom(*New(MiB, Binary))
+	large := NullSizeFrom(*New(GiB, Binary))
+
+	// Comparison
+	assertEqual(t, 0, null.Cmp(NullSize{}), "Comparing null against null")
+	assertEqual(t, -1, null.Cmp(small), "Comparing null against %v", small)
+	assertEqual(t, 1, small.Cmp(null), "Comparing %v against null", small)
+	assertEqual(t, -1, small.Cmp(large), "Comparing %v against %v", small, large)
+	assertEqual(t, true, null.Equal(NullSize{}), "Null equals null")
+	assertEqual(t, false, null.Equal(NullSizeFrom(Size{})), "Null equals zero")
+
+	// Arithmetic
+	sum := small
+	sum.Add(large)
+	assertEqual(t, NullSizeFrom(*New(GiB+MiB, Binary)), sum, "Adding %v + %v", small, large)
+	sum.Sub(small)
+	assertEqual(t, large, sum, "Subtracting %v", small)
+	sum.Add(null)
+	assertEqual(t, null, sum, "Adding null")
+
+	// Formatting
+	assertEqual(t, "", null.String(), "String of null")
+	assertEqual(t, "1 MiB", small.String(), "String of %v", small)
+	assertEqual(t, "[] [1.00 GiB]", fmt.Sprintf("[%.2f] [%.2f]", null, large), "Formatting")
+
+	// Text
+	text, err := null.MarshalText()
+	assertNoErr(t, err, "Marshalling null")
+	assertEqual(t, "", string(text), "Text of null")
+
+	var parsed NullSize
+	if assertNoErr(t, parsed.UnmarshalText([]byte("1MiB")), "Unmarshalling 1MiB") {
+		assertEqual(t, small, parsed, "Unmarshalled 1MiB")
+	}
+	if assertNoErr(t, parsed.UnmarshalText(nil), "Unmarshalling empty text") {
+		assertEqual(t, null, parsed, "Unmarshalled empty text")
+	}
+	assertEqualErr(t, `can't convert "x" to size: must start with a number`,
+		parsed.UnmarshalText([]byte("x")), "Unmarshalling invalid text")
+
+	parsed, err = ParseNull("1 GiB")
+	if assertNoErr(t, err, "Parsing 1 GiB") {
+		assertEqual(t, large, parsed, "Parsed 1 GiB")
+	}
+}
+
+var benchInputs = []string{"0", "1024", "64k", "1.5 GiB", "123.456 GB", "9223372036854775807"}
+
+func BenchmarkParse(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = Parse(benchInputs[i%len(benchInputs)])
+	}
+}
+
+func BenchmarkParseBytes(b *testing.B) {
+	inputs := make([][]byte, len(benchInputs))
+	for i, in := range benchInputs {
+		inputs[i] = []byte(in)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = ParseBytes(inputs[i%len(inputs)])
+	}
+}
+
+func BenchmarkString(b *testing.B) {
+	size := New(1536*MiB, Binary)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = size.String()
+	}
+}
+
+func BenchmarkAppendText(b *testing.B) {
+	size := New(1536*MiB, Binary)
+	buf := make([]byte, 0, 32)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf, _ = size.AppendText(buf[:0])
+	}
+}
+
+func BenchmarkAppendFormat(b *testing.B) {
+	size := New(1536*MiB, Binary)
+	buf := make([]byte, 0, 32)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf = size.AppendFormat(buf[:0], 'g', 4)
+	}
+}
+
 func assertNoErr(t *testing.T, err error, message string, args ...interface{}) bool {
 	t.Helper()
 	if err == nil {
@@ -0,0 +1,169 @@
+// Package cache provides an in-memory cache bounded by the total size of its
+// entries rather than their number.
+package cache
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+
+	"github.com/allenai/bytefmt"
+)
+
+// Cache is a least-recently-used cache whose capacity is measured in bytes. Each
+// entry's size is determined by a cost function, and the least recently used
+// entries are evicted to keep the total within capacity.
+//
+//    capacity, _ := bytefmt.Parse("512MiB")
+//    c := cache.New(*capacity, func(key string, value []byte) bytefmt.Size {
+//        return *bytefmt.New(int64(len(key)+len(value)), bytefmt.Binary)
+//    })
+//
+// Cache is safe for concurrent use.
+type Cache[K comparable, V any] struct {
+	mu       sync.Mutex
+	capacity bytefmt.Size
+	cost     func(K, V) bytefmt.Size
+	order    *list.List // Of *entry, most recently used first
+	entries  map[K]*list.Element
+	size     int64
+
+	hits, misses, evictions uint64
+}
+
+type entry[K comparable, V any] struct {
+	key   K
+	value V
+	cost  int64
+}
+
+// Stats reports a cache's usage. Sizes are in the base of the cache's capacity.
+type Stats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+
+	// Len is the number of entries.
+	Len int
+
+	// Size is the total cost of all entries.
+	Size     bytefmt.Size
+	Capacity bytefmt.Size
+}
+
+// New returns an empty cache which holds entries with a total cost of at most
+// capacity. The cost function returns the size of an entry, which must not
+// change while the entry is cached. Entries with a negative cost are rejected by
+// Add.
+func New[K comparable, V any](capacity bytefmt.Size, cost func(key K, value V) bytefmt.Size) *Cache[K, V] {
+	return &Cache[K, V]{
+		capacity: capacity,
+		cost:     cost,
+		order:    list.New(),
+		entries:  make(map[K]*list.Element),
+	}
+}
+
+// Get returns the value cached for key, marking it most recently used.
+func (c *Cache[K, V]) Get(key K) (value V, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return value, false
+	}
+	c.hits++
+	c.order.MoveToFront(elem)
+	return elem.Value.(*entry[K, V]).value, true
+}
+
+// Add caches a value, replacing any already cached for key, and evicts the least
+// recently used entries until the cache is within capacity. If the entry's cost
+// is negative, Add returns an error, or if the entry alone exceeds the capacity,
+// a *bytefmt.LimitExceededError; either way the cache is unchanged.
+func (c *Cache[K, V]) Add(key K, value V) error {
+	size := c.cost(key, value)
+	if size.Sign() < 0 {
+		return fmt.Errorf("can't cache an entry with a negative cost of %s", size.String())
+	}
+	cost := size.Int64()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cost > c.capacity.Int64() {
+		return &bytefmt.LimitExceededError{Limit: c.capacity, Attempted: c.sizeOf(cost)}
+	}
+
+	if elem, ok := c.entries[key]; ok {
+		e := elem.Value.(*entry[K, V])
+		c.size += cost - e.cost
+		e.value, e.cost = value, cost
+		c.order.MoveToFront(elem)
+	} else {
+		c.entries[key] = c.order.PushFront(&entry[K, V]{key, value, cost})
+		c.size += cost
+	}
+
+	for c.size > c.capacity.Int64() {
+		c.removeElement(c.order.Back())
+		c.evictions++
+	}
+	return nil
+}
+
+// Remove removes the entry for key, returning whether there was one.
+func (c *Cache[K, V]) Remove(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if ok {
+		c.removeElement(elem)
+	}
+	return ok
+}
+
+// Len returns the number of entries in the cache.
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// Size returns the total cost of all entries in the cache.
+func (c *Cache[K, V]) Size() bytefmt.Size {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.sizeOf(c.size)
+}
+
+// Capacity returns the most the entries in the cache may cost in total.
+func (c *Cache[K, V]) Capacity() bytefmt.Size { return c.capacity }
+
+// Stats returns the cache's usage.
+func (c *Cache[K, V]) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return Stats{
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+		Len:       len(c.entries),
+		Size:      c.sizeOf(c.size),
+		Capacity:  c.capacity,
+	}
+}
+
+func (c *Cache[K, V]) removeElement(elem *list.Element) {
+	e := c.order.Remove(elem).(*entry[K, V])
+	delete(c.entries, e.key)
+	c.size -= e.cost
+}
+
+// sizeOf returns a count of bytes in the base of the cache's capacity.
+func (c *Cache[K, V]) sizeOf(n int64) bytefmt.Size {
+	return *bytefmt.New(n, c.capacity.Base)
+}
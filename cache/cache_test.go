@@ -0,0 +1,155 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/allenai/bytefmt"
+)
+
+func byteCost(key string, value []byte) bytefmt.Size {
+	return *bytefmt.New(int64(len(value)), bytefmt.Metric)
+}
+
+func TestCache(t *testing.T) {
+	c := New(*bytefmt.New(10, bytefmt.Binary), byteCost)
+
+	assertNoErr(t, c.Add("a", []byte("aaaa")), "Adding a")
+	assertNoErr(t, c.Add("b", []byte("bbb")), "Adding b")
+	assertNoErr(t, c.Add("c", []byte("cc")), "Adding c")
+	assertEqual(t, *bytefmt.New(9, bytefmt.Binary), c.Size(), "Size")
+
+	// Using a makes b the least recently used.
+	value, ok := c.Get("a")
+	assertEqual(t, true, ok, "Found a")
+	assertEqual(t, "aaaa", string(value), "Value of a")
+
+	assertNoErr(t, c.Add("d", []byte("dd")), "Adding d")
+	_, ok = c.Get("b")
+	assertEqual(t, false, ok, "Found evicted b")
+	assertEqual(t, 3, c.Len(), "Len after eviction")
+	assertEqual(t, *bytefmt.New(8, bytefmt.Binary), c.Size(), "Size after eviction")
+
+	// Replacing an entry updates its cost.
+	assertNoErr(t, c.Add("c", []byte("cccccc")), "Replacing c")
+	assertEqual(t, Stats{
+		Hits:      1,
+		Misses:    1,
+		Evictions: 2,
+		Len:       2,
+		Size:      *bytefmt.New(8, bytefmt.Binary),
+		Capacity:  *bytefmt.New(10, bytefmt.Binary),
+	}, c.Stats(), "Stats after replacing")
+	_, ok = c.Get("a")
+	assertEqual(t, false, ok, "Found evicted a")
+
+	assertEqual(t, true, c.Remove("d"), "Removing d")
+	assertEqual(t, false, c.Remove("d"), "Removing d twice")
+	assertEqual(t, *bytefmt.New(6, bytefmt.Binary), c.Size(), "Size after removal")
+}
+
+func TestCacheTooLarge(t *testing.T) {
+	c := New(*bytefmt.New(bytefmt.KiB, bytefmt.Binary), byteCost)
+	assertNoErr(t, c.Add("small", make([]byte, 100)), "Adding a small entry")
+
+	err := c.Add("small", make([]byte, 2*bytefmt.KiB))
+	assertEqualErr(t, "2 KiB exceeds the limit of 1 KiB", err, "Adding an oversized entry")
+	var limitErr *bytefmt.LimitExceededError
+	assertEqual(t, true, errors.As(err, &limitErr), "Error type")
+
+	// The cache is unchanged.
+	value, ok := c.Get("small")
+	assertEqual(t, true, ok, "Found existing entry")
+	assertEqual(t, 100, len(value), "Existing entry")
+	assertEqual(t, uint64(0), c.Stats().Evictions, "Evictions")
+}
+
+func TestCacheNegativeCost(t *testing.T) {
+	c := New(*bytefmt.New(bytefmt.KiB, bytefmt.Binary), func(key string, cost int64) bytefmt.Size {
+		return *bytefmt.New(cost, bytefmt.Binary)
+	})
+	assertNoErr(t, c.Add("a", 100), "Adding a")
+
+	err := c.Add("a", -2*bytefmt.KiB)
+	assertEqualErr(t, "can't cache an entry with a negative cost of -2 KiB", err, "Replacing with a negative cost")
+	err = c.Add("b", -1)
+	assertEqualErr(t, "can't cache an entry with a negative cost of -1 B", err, "Adding a negative cost")
+
+	// The cache is unchanged.
+	value, ok := c.Get("a")
+	assertEqual(t, true, ok, "Found existing entry")
+	assertEqual(t, int64(100), value, "Existing entry")
+	_, ok = c.Get("b")
+	assertEqual(t, false, ok, "Found rejected entry")
+	assertEqual(t, 1, c.Len(), "Len")
+	assertEqual(t, *bytefmt.New(100, bytefmt.Binary), c.Size(), "Size")
+}
+
+func TestCacheConcurrent(t *testing.T) {
+	c := New(*bytefmt.New(bytefmt.KB, bytefmt.Metric), func(key int, value string) bytefmt.Size {
+		return *bytefmt.New(int64(len(value)), bytefmt.Metric)
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				key := (i*100 + j) % 150
+				if _, ok := c.Get(key); !ok {
+					_ = c.Add(key, fmt.Sprint(key, "-value"))
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	stats := c.Stats()
+	assertEqual(t, uint64(800), stats.Hits+stats.Misses, "Lookups")
+	assertEqual(t, true, stats.Size.Cmp(stats.Capacity) <= 0, "Size within capacity")
+}
+
+func assertNoErr(t *testing.T, err error, message string, args ...interface{}) bool {
+	t.Helper()
+	if err == nil {
+		return true
+	}
+	t.Error(fmt.Sprintf(message, args...),
+		"\n    Error:", err)
+	return false
+}
+
+func assertEqualErr(
+	t *testing.T,
+	expect string,
+	actual error,
+	message string,
+	args ...interface{},
+) bool {
+	t.Helper()
+	if actual != nil {
+		return assertEqual(t, expect, actual.Error(), message, args...)
+	}
+	return assertEqual(t, expect, actual, message, args...)
+}
+
+func assertEqual(
+	t *testing.T,
+	expect interface{},
+	actual interface{},
+	message string,
+	args ...interface{},
+) bool {
+	t.Helper()
+	if reflect.DeepEqual(expect, actual) {
+		return true
+	}
+	t.Error(fmt.Sprintf(message, args...),
+		"\n    Expected:", expect,
+		"\n    Actual:  ", actual)
+	return false
+}
@@ -0,0 +1,102 @@
+package bytefmt
+
+import (
+	"io"
+	"sync/atomic"
+)
+
+// CountingReader counts the bytes read from an underlying reader. It's safe to
+// call Count and Reset concurrently with reads.
+//
+//    r := bytefmt.NewCountingReader(resp.Body, bytefmt.Binary)
+//    io.Copy(dst, r)
+//    log.Printf("downloaded %v", r.Count())
+//
+// CountingReader implements io.WriterTo so that io.Copy can use the underlying
+// reader's fast path, if it has one. In that case bytes are counted only once
+// WriteTo returns.
+type CountingReader struct {
+	n    int64 // Accessed atomically; first for alignment on 32-bit platforms.
+	r    io.Reader
+	base Base
+}
+
+// NewCountingReader returns a reader which counts bytes read from r, reporting
+// them in the given base.
+func NewCountingReader(r io.Reader, base Base) *CountingReader {
+	return &CountingReader{r: r, base: base}
+}
+
+// Read implements the io.Reader interface.
+func (c *CountingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	atomic.AddInt64(&c.n, int64(n))
+	return n, err
+}
+
+// WriteTo implements the io.WriterTo interface.
+func (c *CountingReader) WriteTo(w io.Writer) (int64, error) {
+	if wt, ok := c.r.(io.WriterTo); ok {
+		n, err := wt.WriteTo(w)
+		atomic.AddInt64(&c.n, n)
+		return n, err
+	}
+	// Hide WriteTo to avoid recursion in io.Copy.
+	return io.Copy(w, struct{ io.Reader }{c})
+}
+
+// Count returns the number of bytes read so far.
+func (c *CountingReader) Count() Size {
+	return Size{bytes: atomic.LoadInt64(&c.n), Base: c.base}
+}
+
+// Reset sets the count to zero and returns its previous value.
+func (c *CountingReader) Reset() Size {
+	return Size{bytes: atomic.SwapInt64(&c.n, 0), Base: c.base}
+}
+
+// CountingWriter counts the bytes written to an underlying writer. It's safe to
+// call Count and Reset concurrently with writes.
+//
+// CountingWriter implements io.ReaderFrom so that io.Copy can use the underlying
+// writer's fast path, if it has one. In that case bytes are counted only once
+// ReadFrom returns.
+type CountingWriter struct {
+	n    int64 // Accessed atomically; first for alignment on 32-bit platforms.
+	w    io.Writer
+	base Base
+}
+
+// NewCountingWriter returns a writer which counts bytes written to w, reporting
+// them in the given base.
+func NewCountingWriter(w io.Writer, base Base) *CountingWriter {
+	return &CountingWriter{w: w, base: base}
+}
+
+// Write implements the io.Writer interface.
+func (c *CountingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	atomic.AddInt64(&c.n, int64(n))
+	return n, err
+}
+
+// ReadFrom implements the io.ReaderFrom interface.
+func (c *CountingWriter) ReadFrom(r io.Reader) (int64, error) {
+	if rf, ok := c.w.(io.ReaderFrom); ok {
+		n, err := rf.ReadFrom(r)
+		atomic.AddInt64(&c.n, n)
+		return n, err
+	}
+	// Hide ReadFrom to avoid recursion in io.Copy.
+	return io.Copy(struct{ io.Writer }{c}, r)
+}
+
+// Count returns the number of bytes written so far.
+func (c *CountingWriter) Count() Size {
+	return Size{bytes: atomic.LoadInt64(&c.n), Base: c.base}
+}
+
+// Reset sets the count to zero and returns its previous value.
+func (c *CountingWriter) Reset() Size {
+	return Size{bytes: atomic.SwapInt64(&c.n, 0), Base: c.base}
+}
@@ -0,0 +1,82 @@
+package bytefmt
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+)
+
+func TestCountingReader(t *testing.T) {
+	r := NewCountingReader(iotest.HalfReader(strings.NewReader(strings.Repeat("x", 3000))), Metric)
+	buf := make([]byte, 1000)
+	n, err := r.Read(buf)
+	assertNoErr(t, err, "Reading")
+	assertEqual(t, *New(int64(n), Metric), r.Count(), "Count after read")
+
+	_, err = io.Copy(ioutil.Discard, r)
+	assertNoErr(t, err, "Copying")
+	assertEqual(t, *New(3*KB, Metric), r.Count(), "Count after copy")
+
+	assertEqual(t, *New(3*KB, Metric), r.Reset(), "Count before reset")
+	assertEqual(t, *New(0, Metric), r.Count(), "Count after reset")
+
+	// Errors are passed through with partial reads counted.
+	r = NewCountingReader(iotest.TimeoutReader(strings.NewReader("abcdef")), Binary)
+	_, err = ioutil.ReadAll(r)
+	assertEqualErr(t, iotest.ErrTimeout.Error(), err, "Error from reader")
+	assertEqual(t, *New(6, Binary), r.Count(), "Count after error")
+}
+
+func TestCountingWriter(t *testing.T) {
+	var out bytes.Buffer
+	w := NewCountingWriter(&out, Binary)
+	_, err := w.Write([]byte("hello, "))
+	assertNoErr(t, err, "Writing")
+	assertEqual(t, *New(7, Binary), w.Count(), "Count after write")
+
+	// The destination buffer implements io.ReaderFrom.
+	_, err = io.Copy(w, strings.NewReader(strings.Repeat("x", 2041)))
+	assertNoErr(t, err, "Copying")
+	assertEqual(t, *New(2*KiB, Binary), w.Count(), "Count after copy")
+	assertEqual(t, 2048, out.Len(), "Bytes written")
+
+	// Writers without io.ReaderFrom are written to directly.
+	w = NewCountingWriter(iotest.TruncateWriter(ioutil.Discard, 5), Metric)
+	_, err = io.Copy(w, iotest.OneByteReader(strings.NewReader("abc")))
+	assertNoErr(t, err, "Copying one byte at a time")
+	assertEqual(t, *New(3, Metric), w.Count(), "Count after one byte copy")
+}
+
+func TestCountingPassthrough(t *testing.T) {
+	// Both fast paths pass through when copying between counters.
+	r := NewCountingReader(strings.NewReader(strings.Repeat("x", 5000)), Metric)
+	var out bytes.Buffer
+	w := NewCountingWriter(&out, Metric)
+	n, err := io.Copy(w, r)
+	assertNoErr(t, err, "Copying")
+	assertEqual(t, int64(5000), n, "Bytes copied")
+	assertEqual(t, *New(5*KB, Metric), r.Count(), "Bytes read")
+	assertEqual(t, *New(5*KB, Metric), w.Count(), "Bytes written")
+}
+
+func TestCountingConcurrent(t *testing.T) {
+	w := NewCountingWriter(ioutil.Discard, Binary)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			buf := make([]byte, KiB)
+			for j := 0; j < 128; j++ {
+				_, _ = w.Write(buf)
+				_ = w.Count()
+			}
+		}()
+	}
+	wg.Wait()
+	assertEqual(t, *New(MiB, Binary), w.Count(), "Count after concurrent writes")
+}
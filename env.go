@@ -0,0 +1,134 @@
+package bytefmt
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// EnvError records a variable which couldn't be loaded.
+type EnvError struct {
+	Name string
+	Err  error
+}
+
+func (e *EnvError) Error() string {
+	return fmt.Sprintf("variable %s: %v", e.Name, e.Err)
+}
+
+func (e *EnvError) Unwrap() error { return e.Err }
+
+// LookupEnv parses the size held in the named environment variable. If the
+// variable is unset, it returns value.
+func LookupEnv(name string, value Size) (Size, error) {
+	str, ok := os.LookupEnv(name)
+	if !ok {
+		return value, nil
+	}
+
+	size, err := Parse(str)
+	if err != nil {
+		return Size{}, &EnvError{Name: name, Err: err}
+	}
+	return *size, nil
+}
+
+// MustEnv is like LookupEnv but panics if the variable can't be parsed. It
+// simplifies initialization of global variables holding sizes.
+func MustEnv(name string, value Size) Size {
+	size, err := LookupEnv(name, value)
+	if err != nil {
+		panic(err)
+	}
+	return size
+}
+
+// LoadEnv sets fields within the struct pointed to by v from environment
+// variables. See Load for details.
+func LoadEnv(v interface{}) error {
+	return Load(v, os.LookupEnv)
+}
+
+// LoadMap sets fields within the struct pointed to by v from a map of variables,
+// such as those in a ConfigMap or .env file. See Load for details.
+func LoadMap(v interface{}, vars map[string]string) error {
+	return Load(v, func(name string) (string, bool) {
+		value, ok := vars[name]
+		return value, ok
+	})
+}
+
+// Load sets fields within the struct pointed to by v from variables named by
+// their "env" struct tags. Tagged fields must be of type Size, NullSize, or
+// []Size. Fields whose variables are not found are left unchanged, making it
+// simple to set defaults before loading.
+//
+// Slices are read as comma-separated lists. An empty string sets a NullSize to
+// null. Untagged struct fields are loaded recursively.
+//
+//    type Config struct {
+//        Cache  bytefmt.Size     `env:"CACHE_SIZE"`
+//        Quota  bytefmt.NullSize `env:"QUOTA"`
+//        Shards []bytefmt.Size   `env:"SHARD_SIZES"`
+//    }
+func Load(v interface{}, lookup func(name string) (string, bool)) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("can't load into non-pointer to struct")
+	}
+	return load(rv.Elem(), "", lookup)
+}
+
+var sizeSliceType = reflect.TypeOf([]Size(nil))
+
+func load(v reflect.Value, path string, lookup func(string) (string, bool)) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue // Unexported
+		}
+
+		fieldPath := joinPath(path, field.Name)
+		name, tagged := field.Tag.Lookup("env")
+		if !tagged || name == "" {
+			if field.Type.Kind() == reflect.Struct && field.Type != sizeType &&
+				field.Type != nullSizeType {
+				if err := load(v.Field(i), fieldPath, lookup); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		// Report unsupported fields whether or not their variables are set.
+		if field.Type != sizeType && field.Type != nullSizeType && field.Type != sizeSliceType {
+			err := fmt.Errorf("can't load into field %s of type %s", fieldPath, field.Type)
+			return &EnvError{Name: name, Err: err}
+		}
+
+		str, ok := lookup(name)
+		if !ok {
+			continue
+		}
+
+		var err error
+		switch p := v.Field(i).Addr().Interface().(type) {
+		case *Size:
+			err = p.Set(str)
+		case *NullSize:
+			err = p.Set(str)
+		case *[]Size:
+			var sizes []Size
+			if sizes, err = parseList(strings.Split(str, ",")); err == nil {
+				*p = sizes
+			}
+		}
+		if err != nil {
+			return &EnvError{Name: name, Err: err}
+		}
+	}
+	return nil
+}
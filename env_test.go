@@ -0,0 +1,105 @@
+package bytefmt
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestLookupEnv(t *testing.T) {
+	const name = "BYTEFMT_TEST_CACHE_SIZE"
+	fallback := *New(KB, Metric)
+
+	os.Unsetenv(name)
+	size, err := LookupEnv(name, fallback)
+	if assertNoErr(t, err, "Looking up unset variable") {
+		assertEqual(t, fallback, size, "Unset variable")
+	}
+
+	os.Setenv(name, "512MiB")
+	defer os.Unsetenv(name)
+	size, err = LookupEnv(name, fallback)
+	if assertNoErr(t, err, "Looking up set variable") {
+		assertEqual(t, *New(512*MiB, Binary), size, "Set variable")
+	}
+	assertEqual(t, *New(512*MiB, Binary), MustEnv(name, fallback), "Must variable")
+
+	os.Setenv(name, "lots")
+	_, err = LookupEnv(name, fallback)
+	assertEqualErr(t, `variable BYTEFMT_TEST_CACHE_SIZE: `+
+		`can't convert "lots" to size: must start with a number`, err, "Invalid variable")
+
+	var envErr *EnvError
+	if assertEqual(t, true, errors.As(err, &envErr), "Error type") {
+		assertEqual(t, name, envErr.Name, "Error name")
+	}
+
+	defer func() {
+		assertEqual(t, err, recover(), "Must panic")
+	}()
+	MustEnv(name, fallback)
+}
+
+func TestLoadMap(t *testing.T) {
+	type nested struct {
+		Spill Size `env:"SPILL"`
+	}
+
+	type config struct {
+		Cache  Size     `env:"CACHE_SIZE"`
+		Quota  NullSize `env:"QUOTA"`
+		Limit  NullSize `env:"LIMIT"`
+		Shards []Size   `env:"SHARD_SIZES"`
+		Nested nested
+		Unset  Size `env:"UNSET"`
+	}
+
+	c := config{
+		Limit: NullSize{Size: *New(GB, Metric), Valid: true},
+		Unset: *New(KB, Metric),
+	}
+	err := LoadMap(&c, map[string]string{
+		"CACHE_SIZE":  "512MiB",
+		"QUOTA":       "10g",
+		"LIMIT":       "",
+		"SHARD_SIZES": "1MiB, 2MiB",
+		"SPILL":       "32 MiB",
+	})
+	if !assertNoErr(t, err, "Loading map") {
+		return
+	}
+
+	expect := config{
+		Cache:  *New(512*MiB, Binary),
+		Quota:  NullSize{Size: *New(10*GB, Metric), Valid: true},
+		Shards: []Size{*New(MiB, Binary), *New(2*MiB, Binary)},
+		Nested: nested{Spill: *New(32*MiB, Binary)},
+		Unset:  *New(KB, Metric),
+	}
+	assertEqual(t, expect, c, "Loaded config")
+}
+
+func TestLoadErrors(t *testing.T) {
+	var c struct {
+		Shards []Size `env:"SHARD_SIZES"`
+		Nested struct {
+			Count int `env:"COUNT"`
+		}
+	}
+
+	err := LoadMap(c, nil)
+	assertEqualErr(t, "can't load into non-pointer to struct", err, "Loading non-pointer")
+
+	err = LoadMap(&c, map[string]string{"SHARD_SIZES": "1MiB,,2MiB"})
+	assertEqualErr(t, `variable SHARD_SIZES: can't convert "" to size: empty string`, err,
+		"Loading invalid slice")
+
+	err = LoadMap(&c, map[string]string{"COUNT": "1"})
+	assertEqualErr(t, "variable COUNT: can't load into field Nested.Count of type int", err,
+		"Loading unsupported field")
+
+	// Unsupported fields are reported even if their variables aren't set.
+	err = LoadMap(&c, nil)
+	assertEqualErr(t, "variable COUNT: can't load into field Nested.Count of type int", err,
+		"Loading unsupported unset field")
+}
@@ -0,0 +1,275 @@
+package bytefmt
+
+import (
+	"bufio"
+	"io"
+)
+
+// Match is a byte quantity found within text.
+type Match struct {
+	// Start and End are the byte offsets of the match, such that the matched text
+	// is text[Start:End].
+	Start, End int
+
+	Text string
+	Size Size
+}
+
+// Finder locates byte quantities within free-form text. The zero value is ready
+// to use.
+//
+// A match is any word which Parse accepts, such as "4GiB" or "3.2 GiB", where a
+// word must not be joined to letters or digits on either side. Numbers within a
+// run of more than 64 digits and decimal points are never matched, which bounds
+// how much of a stream must be buffered.
+type Finder struct {
+	// Bare determines whether numbers without a unit are matched as counts of
+	// bytes. Since most numbers in text aren't sizes, they are skipped by default.
+	Bare bool
+}
+
+// FindAll returns all sizes with units in text, in order.
+//
+//    FindAll("evicted 3.2 GiB after reaching 90% of 4GiB limit")
+//        = [{8 15 "3.2 GiB"}, {36 40 "4GiB"}]
+func FindAll(text string) []Match {
+	return Finder{}.FindAll(text)
+}
+
+// FindAll returns all sizes in text, in order.
+func (f Finder) FindAll(text string) []Match {
+	data := []byte(text)
+
+	var matches []Match
+	for pos := 0; pos < len(data); {
+		m, ok, resume := f.find(data, pos, true)
+		if !ok {
+			break
+		}
+		matches = append(matches, m)
+		pos = resume
+	}
+	return matches
+}
+
+// ScanSizes is a bufio.SplitFunc which returns each size with a unit as a token.
+func ScanSizes(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	return Finder{}.Split(data, atEOF)
+}
+
+// Split is a bufio.SplitFunc which returns each size matched by f as a token.
+func (f Finder) Split(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	m, ok, resume := f.find(data, 0, atEOF)
+	if ok {
+		return resume, data[m.Start:m.End], nil
+	}
+	return resume, nil, nil
+}
+
+// MatchScanner reads sizes from a stream. Successive calls to Scan step through
+// each match, which is available from Match.
+type MatchScanner struct {
+	scanner *bufio.Scanner
+	offset  int
+	match   Match
+}
+
+// NewMatchScanner returns a scanner which reads sizes matched by f from r.
+// Offsets of each match are relative to the start of the stream.
+func NewMatchScanner(r io.Reader, f Finder) *MatchScanner {
+	s := &MatchScanner{scanner: bufio.NewScanner(r)}
+	s.scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		m, ok, resume := f.find(data, 0, atEOF)
+		if !ok {
+			s.offset += resume
+			return resume, nil, nil
+		}
+
+		advance := resume
+		m.Start += s.offset
+		m.End += s.offset
+		s.match = m
+		s.offset += advance
+		return advance, []byte(m.Text), nil
+	})
+	return s
+}
+
+// Scan advances to the next match, returning false when the stream ends or an
+// error occurs.
+func (s *MatchScanner) Scan() bool { return s.scanner.Scan() }
+
+// Match returns the most recent match found by Scan.
+func (s *MatchScanner) Match() Match { return s.match }
+
+// Err returns the first non-EOF error encountered while reading.
+func (s *MatchScanner) Err() error { return s.scanner.Err() }
+
+// Limits on the length of a candidate, beyond which it can't be a size.
+const (
+	maxNumberLen = 64 // Digits and decimal points
+	maxSuffixLen = 3  // As in "KiB"
+)
+
+// find returns the first match in data at or after from, and where to resume
+// searching after it. If there is none, it returns how far the caller may
+// advance before searching again with more data: all of it at EOF, otherwise up
+// to the first unresolved candidate, or enough of a trailing word that the next
+// search doesn't mistake its remainder for the start of a word.
+func (f Finder) find(data []byte, from int, atEOF bool) (m Match, ok bool, resume int) {
+	resume = len(data)
+	if !atEOF {
+		// A letter is enough to show that a word continues, since none can begin a
+		// candidate. Otherwise hold back digits and decimal points, but no more
+		// than one beyond the longest number.
+		for resume > from && isWordByte(data[resume-1]) && len(data)-resume <= maxNumberLen {
+			resume--
+			if isLetter(data[resume]) || data[resume] == '_' {
+				break
+			}
+		}
+	}
+
+	for i := from; i < len(data); i++ {
+		c := data[i]
+		if (c != '-' && c != '.' && !isDigit(c)) || (i != 0 && isWordByte(data[i-1])) {
+			continue
+		}
+
+		end, complete := f.candidate(data, i, atEOF)
+		if !complete && !atEOF {
+			if i < resume {
+				resume = i
+			}
+			return Match{}, false, resume
+		}
+		if end < 0 {
+			continue
+		}
+
+		text := string(data[i:end])
+		size, err := parse(text)
+		if err != nil {
+			continue // The value overflows, so it can't be a size.
+		}
+		// A hyphen joined to the match can't begin another, as in "4GiB-5GiB", but
+		// would appear to once the match is consumed.
+		resume = end
+		if resume < len(data) && data[resume] == '-' {
+			resume++
+		}
+		return Match{Start: i, End: end, Text: text, Size: *size}, true, resume
+	}
+	return Match{}, false, resume
+}
+
+// candidate returns the end of a size beginning at data[i], or -1 if there is
+// none. It returns false if the result depends on what follows data.
+func (f Finder) candidate(data []byte, i int, atEOF bool) (end int, complete bool) {
+	n := len(data)
+	pos := i
+
+	// Number
+	if data[pos] == '-' {
+		pos++
+	}
+	digits := pos
+	run := pos
+	for run < n && (isDigit(data[run]) || data[run] == '.') {
+		if run-digits == maxNumberLen {
+			return -1, true // Too long to be a number
+		}
+		run++
+	}
+	if run == n && !atEOF {
+		return -1, false
+	}
+	for pos < n && isDigit(data[pos]) {
+		pos++
+	}
+	if pos < n && data[pos] == '.' {
+		if pos+1 == n && !atEOF {
+			return -1, false
+		}
+		if pos+1 < n && isDigit(data[pos+1]) {
+			pos++
+			for pos < n && isDigit(data[pos]) {
+				pos++
+			}
+		}
+	}
+	if pos == n && !atEOF {
+		return -1, false
+	}
+	if pos == digits {
+		return -1, true // Not a number
+	}
+	number := pos
+
+	// Unit, either attached or following a single space
+	unit := pos
+	if pos < n && data[pos] == ' ' {
+		unit++
+		if unit == n && !atEOF {
+			return -1, false
+		}
+	}
+	for pos = unit; pos < n && isLetter(data[pos]) && pos-unit <= maxSuffixLen; pos++ {
+	}
+	if pos-unit > maxSuffixLen {
+		if unit == number {
+			return -1, true // Joined to a word, as in "4times"
+		}
+	} else if pos > unit {
+		wordEnd, complete := isWordEnd(data, pos, atEOF)
+		if !complete {
+			return -1, false
+		}
+		if wordEnd {
+			if _, _, err := parseSuffix(string(data[unit:pos])); err == nil {
+				return pos, true
+			}
+		}
+		if unit == number {
+			return -1, true // Joined to other text, as in "4th"
+		}
+	}
+
+	// Bare number
+	if !f.Bare {
+		return -1, true
+	}
+	wordEnd, complete := isWordEnd(data, number, atEOF)
+	if !complete {
+		return -1, false
+	}
+	if !wordEnd {
+		return -1, true
+	}
+	return number, true
+}
+
+// isWordEnd returns whether a word may end before data[pos]. It returns false if
+// the result depends on what follows data.
+func isWordEnd(data []byte, pos int, atEOF bool) (ok, complete bool) {
+	switch {
+	case pos == len(data):
+		return true, atEOF
+	case data[pos] == '.':
+		// Allow a full stop, but not a decimal point.
+		if pos+1 == len(data) {
+			return true, atEOF
+		}
+		return !isDigit(data[pos+1]), true
+	default:
+		return !isWordByte(data[pos]), true
+	}
+}
+
+func isWordByte(c byte) bool {
+	return isDigit(c) || isLetter(c) || c == '.' || c == '_'
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+func isLetter(c byte) bool { return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') }
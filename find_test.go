@@ -0,0 +1,87 @@
+package bytefmt
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestFindAll(t *testing.T) {
+	tests := []struct {
+		In     string
+		Bare   bool
+		Expect []string
+	}{
+		{In: "evicted 3.2 GiB after reaching 90% of 4GiB limit", Expect: []string{"3.2 GiB", "4GiB"}},
+		{In: "evicted 3.2 GiB after reaching 90% of 4GiB limit", Bare: true,
+			Expect: []string{"3.2 GiB", "90", "4GiB"}},
+
+		// Text at the edges
+		{In: "4GiB", Expect: []string{"4GiB"}},
+		{In: "4 GiB.", Expect: []string{"4 GiB"}},
+		{In: "-1.5k,.5MB;(2 gb)", Expect: []string{"-1.5k", ".5MB", "2 gb"}},
+		{In: "42", Bare: true, Expect: []string{"42"}},
+		{In: "42.", Bare: true, Expect: []string{"42"}},
+
+		// Sizes must stand alone.
+		{In: "x86 v1.2GB 4GiBs 10GB2 b4k", Expect: nil},
+		{In: "version 1.2.3 on 4th core", Bare: true, Expect: nil},
+		{In: "10-20 GB", Expect: []string{"20 GB"}},
+		{In: "4GiB-5GiB", Expect: []string{"4GiB", "5GiB"}},
+
+		// Unknown or missing units
+		{In: "512 bytes in 3 blocks", Expect: nil},
+		{In: "512 bytes in 3 blocks", Bare: true, Expect: []string{"512", "3"}},
+		{In: "5  GB", Bare: true, Expect: []string{"5"}},
+
+		// Values which can't be represented are skipped.
+		{In: "9 EiB then 1 EiB", Expect: []string{"1 EiB"}},
+
+		// Long words don't need to be buffered whole.
+		{In: strings.Repeat("x", 70000) + " 4GiB", Expect: []string{"4GiB"}},
+		{In: strings.Repeat("7", 70000) + " 4GiB", Bare: true, Expect: []string{"4GiB"}},
+		{In: strings.Repeat("x", 70000) + "1 GiB", Expect: nil},
+		{In: strings.Repeat("1.", 35000) + "5 GiB 6 GiB", Expect: []string{"6 GiB"}},
+		{In: "4 " + strings.Repeat("g", 70000) + " 2", Bare: true, Expect: []string{"4", "2"}},
+		{In: strings.Repeat("0", 63) + "1 kB " + strings.Repeat("0", 64) + "1 kB", Expect: []string{strings.Repeat("0", 63) + "1 kB"}},
+	}
+
+	for _, test := range tests {
+		f := Finder{Bare: test.Bare}
+		matches := f.FindAll(test.In)
+
+		var texts []string
+		for _, m := range matches {
+			texts = append(texts, m.Text)
+			assertEqual(t, m.Text, test.In[m.Start:m.End], "Offsets of %q in %q", m.Text, test.In)
+			size, err := Parse(m.Text)
+			if assertNoErr(t, err, "Parsing %q", m.Text) {
+				assertEqual(t, *size, m.Size, "Size of %q", m.Text)
+			}
+		}
+		assertEqual(t, test.Expect, texts, "Matches in %q (bare=%v)", test.In, test.Bare)
+
+		// Streaming one byte at a time must find the same matches.
+		s := NewMatchScanner(iotest.OneByteReader(strings.NewReader(test.In)), f)
+		var streamed []Match
+		for s.Scan() {
+			streamed = append(streamed, s.Match())
+		}
+		assertNoErr(t, s.Err(), "Streaming %q", test.In)
+		assertEqual(t, matches, streamed, "Streamed matches in %q (bare=%v)", test.In, test.Bare)
+	}
+}
+
+func TestScanSizes(t *testing.T) {
+	in := "used 3.2 GiB of 4GiB\nfree 800 MiB (20%)\n"
+	s := bufio.NewScanner(iotest.HalfReader(strings.NewReader(in)))
+	s.Split(ScanSizes)
+
+	var tokens []string
+	for s.Scan() {
+		tokens = append(tokens, s.Text())
+	}
+	assertNoErr(t, s.Err(), "Scanning %q", in)
+	assertEqual(t, []string{"3.2 GiB", "4GiB", "800 MiB"}, tokens, "Tokens in %q", in)
+}
@@ -0,0 +1,139 @@
+package bytefmt
+
+import (
+	"flag"
+	"strings"
+)
+
+// Set implements the flag.Value interface.
+func (s *Size) Set(value string) error {
+	size, err := Parse(value)
+	if err != nil {
+		return err
+	}
+	*s = *size
+	return nil
+}
+
+// Type describes the value for command-line help. It implements pflag.Value.
+func (s *Size) Type() string { return "size" }
+
+// Set implements the flag.Value interface. An empty string sets the size to null.
+func (s *NullSize) Set(value string) error {
+	return s.UnmarshalText([]byte(value))
+}
+
+// Type describes the value for command-line help. It implements pflag.Value.
+func (s *NullSize) Type() string { return "size" }
+
+// Flag defines a size flag with the specified name, default value, and usage
+// string. The return value is the address of a Size that stores the flag's value.
+func Flag(fs *flag.FlagSet, name string, value Size, usage string) *Size {
+	p := new(Size)
+	FlagVar(fs, p, name, value, usage)
+	return p
+}
+
+// FlagVar defines a size flag with the specified name, default value, and usage
+// string. The argument p points to a Size in which to store the flag's value.
+func FlagVar(fs *flag.FlagSet, p *Size, name string, value Size, usage string) {
+	*p = value
+	fs.Var(p, name, usage)
+}
+
+// SizeSlice is a flag value which collects a list of sizes. The flag may be
+// repeated, and each occurrence may hold several comma-separated sizes. The first
+// occurrence replaces the default rather than appending to it.
+type SizeSlice struct {
+	p       *[]Size
+	changed bool
+}
+
+// NewSizeSlice returns a flag value which stores sizes in p. Any sizes already in
+// p are treated as the default.
+func NewSizeSlice(p *[]Size) *SizeSlice {
+	return &SizeSlice{p: p}
+}
+
+// SliceFlag defines a size list flag with the specified name, default value, and
+// usage string. The return value is the address of a slice that stores the flag's
+// values.
+func SliceFlag(fs *flag.FlagSet, name string, value []Size, usage string) *[]Size {
+	p := new([]Size)
+	SliceFlagVar(fs, p, name, value, usage)
+	return p
+}
+
+// SliceFlagVar defines a size list flag with the specified name, default value,
+// and usage string. The argument p points to a slice in which to store the flag's
+// values.
+func SliceFlagVar(fs *flag.FlagSet, p *[]Size, name string, value []Size, usage string) {
+	*p = append([]Size(nil), value...)
+	fs.Var(NewSizeSlice(p), name, usage)
+}
+
+// String returns the sizes as a comma-separated list.
+func (s *SizeSlice) String() string {
+	if s == nil || s.p == nil {
+		return ""
+	}
+	return strings.Join(s.GetSlice(), ",")
+}
+
+// Set implements the flag.Value interface.
+func (s *SizeSlice) Set(value string) error {
+	sizes, err := parseList(strings.Split(value, ","))
+	if err != nil {
+		return err
+	}
+	if !s.changed {
+		*s.p = nil
+		s.changed = true
+	}
+	*s.p = append(*s.p, sizes...)
+	return nil
+}
+
+// Type describes the value for command-line help. It implements pflag.Value.
+func (s *SizeSlice) Type() string { return "sizeSlice" }
+
+// Append adds a single size to the list. It implements pflag.SliceValue.
+func (s *SizeSlice) Append(value string) error {
+	size, err := Parse(strings.TrimSpace(value))
+	if err != nil {
+		return err
+	}
+	*s.p = append(*s.p, *size)
+	return nil
+}
+
+// Replace overwrites the list. It implements pflag.SliceValue.
+func (s *SizeSlice) Replace(values []string) error {
+	sizes, err := parseList(values)
+	if err != nil {
+		return err
+	}
+	*s.p = sizes
+	return nil
+}
+
+// GetSlice returns the formatted sizes. It implements pflag.SliceValue.
+func (s *SizeSlice) GetSlice() []string {
+	strs := make([]string, len(*s.p))
+	for i, size := range *s.p {
+		strs[i] = size.String()
+	}
+	return strs
+}
+
+func parseList(values []string) ([]Size, error) {
+	sizes := make([]Size, len(values))
+	for i, value := range values {
+		size, err := Parse(strings.TrimSpace(value))
+		if err != nil {
+			return nil, err
+		}
+		sizes[i] = *size
+	}
+	return sizes, nil
+}
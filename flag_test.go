@@ -0,0 +1,77 @@
+package bytefmt
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"testing"
+)
+
+func TestFlag(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	cache := Flag(fs, "cache", *New(512*MiB, Binary), "cache size")
+	spill := Flag(fs, "spill", Size{}, "spill threshold")
+	var quota NullSize
+	fs.Var(&quota, "quota", "optional quota")
+	shards := SliceFlag(fs, "shard", []Size{*New(GB, Metric)}, "shard sizes")
+
+	err := fs.Parse([]string{
+		"-spill", "1.5GiB",
+		"-quota", "10g",
+		"-shard", "1MiB,2 MiB",
+		"-shard", "3mib",
+	})
+	if !assertNoErr(t, err, "Parsing flags") {
+		return
+	}
+
+	assertEqual(t, *New(512*MiB, Binary), *cache, "Default value")
+	assertEqual(t, *New(1536*MiB, Binary), *spill, "Parsed value")
+	assertEqual(t, NullSize{Size: *New(10*GB, Metric), Valid: true}, quota, "Parsed null value")
+	assertEqual(t, []Size{*New(MiB, Binary), *New(2*MiB, Binary), *New(3*MiB, Binary)}, *shards,
+		"Parsed slice")
+
+	err = fs.Parse([]string{"-cache", "lots"})
+	assertEqualErr(t, `invalid value "lots" for flag -cache: `+
+		`can't convert "lots" to size: must start with a number`, err, "Invalid value")
+
+	assertNoErr(t, fs.Parse([]string{"-quota", ""}), "Clearing null value")
+	assertEqual(t, NullSize{}, quota, "Cleared null value")
+}
+
+func TestFlagDefaults(t *testing.T) {
+	var out bytes.Buffer
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(&out)
+	Flag(fs, "cache", *New(512*MiB, Binary), "cache `size`")
+	Flag(fs, "spill", Size{}, "spill threshold")
+	SliceFlag(fs, "shard", []Size{*New(GB, Metric), *New(1536, Binary)}, "shard sizes")
+	fs.PrintDefaults()
+
+	expect := "  -cache size\n" +
+		"    \tcache size (default 512 MiB)\n" +
+		"  -shard value\n" +
+		"    \tshard sizes (default 1 GB,1536 B)\n" +
+		"  -spill value\n" +
+		"    \tspill threshold\n"
+	assertEqual(t, expect, out.String(), "Help output")
+}
+
+func TestSizeSliceValue(t *testing.T) {
+	sizes := []Size{*New(KB, Metric)}
+	value := NewSizeSlice(&sizes)
+
+	assertEqual(t, "sizeSlice", value.Type(), "Type")
+	assertEqual(t, []string{"1 kB"}, value.GetSlice(), "Default slice")
+
+	assertNoErr(t, value.Append("2 KiB"), "Appending")
+	assertEqual(t, "1 kB,2 KiB", value.String(), "Appended slice")
+
+	assertNoErr(t, value.Replace([]string{"3MB", " 4 MB"}), "Replacing")
+	assertEqual(t, "3 MB,4 MB", value.String(), "Replaced slice")
+
+	assertEqualErr(t, `can't convert "x" to size: must start with a number`,
+		value.Replace([]string{"1", "x"}), "Replacing with invalid value")
+	assertEqual(t, "3 MB,4 MB", value.String(), "Slice after failed replace")
+}
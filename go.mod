@@ -1,3 +1,3 @@
 module github.com/allenai/bytefmt
 
-go 1.13
+go 1.18
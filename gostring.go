@@ -0,0 +1,137 @@
+package bytefmt
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// Named constants, from largest to smallest, from which Go expressions are built.
+var goUnits = []struct {
+	name  string
+	bytes int64
+}{
+	{"EiB", EiB},
+	{"EB", EB},
+	{"PiB", PiB},
+	{"PB", PB},
+	{"TiB", TiB},
+	{"TB", TB},
+	{"GiB", GiB},
+	{"GB", GB},
+	{"MiB", MiB},
+	{"MB", MB},
+	{"KiB", KiB},
+	{"KB", KB},
+}
+
+// GoString returns a Go expression which evaluates to the size, scaled by the
+// largest exact constant in either base. It implements the fmt.GoStringer
+// interface, and so determines how a size is printed by the %#v verb.
+//
+//    New(3*GiB, Binary).GoString() = "*bytefmt.New(3*bytefmt.GiB, bytefmt.Binary)"
+//    New(1500, Metric).GoString()  = "*bytefmt.New(1500, bytefmt.Metric)"
+//
+// The expression dereferences the result of New, so its type is Size.
+func (s Size) GoString() string {
+	return "*bytefmt.New(" + goBytes(s.bytes, false) + ", " + goBase(s.Base) + ")"
+}
+
+// GoString returns a Go expression which evaluates to the size, as in
+// Size.GoString. It implements the fmt.GoStringer interface.
+//
+//    NullSize{}.GoString() = "bytefmt.NullSize{}"
+//    NullSizeFrom(*New(KB, Metric)).GoString()
+//        = "bytefmt.NullSizeFrom(*bytefmt.New(bytefmt.KB, bytefmt.Metric))"
+func (s NullSize) GoString() string {
+	if !s.Valid {
+		return "bytefmt.NullSize{}"
+	}
+	return "bytefmt.NullSizeFrom(" + s.Size.GoString() + ")"
+}
+
+// WriteConsts writes a Go constant declaration of each named size to w, sorted
+// by name. Since constants can't hold a base, each is written as a count of
+// bytes scaled by the largest exact constant, as in GoString, or by Byte if
+// there is none, so that every constant is an int64.
+//
+//    WriteConsts(w, map[string]Size{"DefaultCacheSize": *New(512*MiB, Binary)})
+//
+// writes
+//
+//    const (
+//        DefaultCacheSize = 512 * bytefmt.MiB
+//    )
+//
+// An error is returned if any name isn't a valid Go identifier.
+func WriteConsts(w io.Writer, sizes map[string]Size) error {
+	names := make([]string, 0, len(sizes))
+	for name := range sizes {
+		if !token.IsIdentifier(name) {
+			return fmt.Errorf("%q is not a valid Go identifier", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b bytes.Buffer
+	b.WriteString("const (\n")
+	for _, name := range names {
+		expr := goBytes(sizes[name].bytes, true)
+		if _, err := strconv.ParseInt(expr, 10, 64); err == nil {
+			expr += " * bytefmt.Byte" // Untyped otherwise
+		}
+		fmt.Fprintf(&b, "\t%s = %s\n", name, expr)
+	}
+	b.WriteString(")\n")
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+// goBytes returns a Go expression for a count of bytes. Operators are spaced as
+// gofmt would for a top-level expression if spaced is true, or within a call
+// otherwise.
+func goBytes(n int64, spaced bool) string {
+	if n == 0 {
+		return "0"
+	}
+
+	for _, u := range goUnits {
+		if n%u.bytes != 0 {
+			continue
+		}
+
+		name := "bytefmt." + u.name
+		switch mult := n / u.bytes; {
+		case mult == 1:
+			return name
+		case mult == -1:
+			return "-" + name
+		case spaced:
+			return strconv.FormatInt(mult, 10) + " * " + name
+		default:
+			return strconv.FormatInt(mult, 10) + "*" + name
+		}
+	}
+	return strconv.FormatInt(n, 10)
+}
+
+func goBase(base Base) string {
+	switch base {
+	case Metric:
+		return "bytefmt.Metric"
+	case Binary:
+		return "bytefmt.Binary"
+	default:
+		return "bytefmt.Base(" + strconv.Itoa(int(base)) + ")"
+	}
+}
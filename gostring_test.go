@@ -0,0 +1,68 @@
+package bytefmt
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestGoString(t *testing.T) {
+	tests := []struct {
+		In     Size
+		Expect string
+	}{
+		{In: Size{}, Expect: "*bytefmt.New(0, bytefmt.Base(0))"},
+		{In: *New(0, Metric), Expect: "*bytefmt.New(0, bytefmt.Metric)"},
+		{In: *New(1500, Metric), Expect: "*bytefmt.New(1500, bytefmt.Metric)"},
+		{In: *New(-1, Binary), Expect: "*bytefmt.New(-1, bytefmt.Binary)"},
+
+		// The largest exact constant is chosen regardless of base.
+		{In: *New(3*GiB, Binary), Expect: "*bytefmt.New(3*bytefmt.GiB, bytefmt.Binary)"},
+		{In: *New(3*GiB, Metric), Expect: "*bytefmt.New(3*bytefmt.GiB, bytefmt.Metric)"},
+		{In: *New(1024*KB, Metric), Expect: "*bytefmt.New(1000*bytefmt.KiB, bytefmt.Metric)"},
+		{In: *New(KB, Binary), Expect: "*bytefmt.New(bytefmt.KB, bytefmt.Binary)"},
+		{In: *New(-2*TB, Metric), Expect: "*bytefmt.New(-2*bytefmt.TB, bytefmt.Metric)"},
+		{In: *New(-PiB, Binary), Expect: "*bytefmt.New(-bytefmt.PiB, bytefmt.Binary)"},
+		{In: *New(-8*EiB, Binary), Expect: "*bytefmt.New(-8*bytefmt.EiB, bytefmt.Binary)"},
+		{In: *New(9*EB, Metric), Expect: "*bytefmt.New(9*bytefmt.EB, bytefmt.Metric)"},
+	}
+
+	for _, test := range tests {
+		assertEqual(t, test.Expect, test.In.GoString(), "GoString of %d bytes", test.In.Int64())
+	}
+
+	assertEqual(t, "*bytefmt.New(3*bytefmt.GiB, bytefmt.Binary)", fmt.Sprintf("%#v", *New(3*GiB, Binary)),
+		"Formatted with %%#v")
+	assertEqual(t, "[]bytefmt.Size{*bytefmt.New(bytefmt.KB, bytefmt.Metric)}",
+		fmt.Sprintf("%#v", []Size{*New(KB, Metric)}), "Formatted in a slice")
+
+	assertEqual(t, "bytefmt.NullSize{}", fmt.Sprintf("%#v", NullSize{}), "Null size")
+	assertEqual(t, "bytefmt.NullSizeFrom(*bytefmt.New(bytefmt.MiB, bytefmt.Binary))",
+		fmt.Sprintf("%#v", NullSizeFrom(*New(MiB, Binary))), "Valid null size")
+}
+
+func TestWriteConsts(t *testing.T) {
+	var b bytes.Buffer
+	err := WriteConsts(&b, map[string]Size{
+		"MaxUpload":        *New(5*GB, Metric),
+		"DefaultCacheSize": *New(512*MiB, Binary),
+		"BlockSize":        *New(4096, Binary),
+		"Odd":              *New(1500, Metric),
+		"Zero":             {},
+	})
+	if !assertNoErr(t, err, "Writing constants") {
+		return
+	}
+
+	expect := "const (\n" +
+		"\tBlockSize        = 4 * bytefmt.KiB\n" +
+		"\tDefaultCacheSize = 512 * bytefmt.MiB\n" +
+		"\tMaxUpload        = 5 * bytefmt.GB\n" +
+		"\tOdd              = 1500 * bytefmt.Byte\n" +
+		"\tZero             = 0 * bytefmt.Byte\n" +
+		")\n"
+	assertEqual(t, expect, b.String(), "Constants")
+
+	err = WriteConsts(&b, map[string]Size{"cache-size": {}})
+	assertEqualErr(t, `"cache-size" is not a valid Go identifier`, err, "Invalid name")
+}
@@ -0,0 +1,195 @@
+package bytefmt
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+)
+
+// NumericSize is a Size which is encoded as an integer count of bytes in JSON,
+// YAML, and text, and in SQL, where it suits a BIGINT column. It decodes from
+// any form accepted by Size. Binary encodings are the same as Size's.
+//
+//    {"cache": 1610612736}
+type NumericSize struct{ Size }
+
+// MarshalJSON implements the json.Marshaler interface.
+func (s NumericSize) MarshalJSON() ([]byte, error) {
+	return strconv.AppendInt(nil, s.bytes, 10), nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (s NumericSize) MarshalText() ([]byte, error) {
+	return strconv.AppendInt(nil, s.bytes, 10), nil
+}
+
+// MarshalYAML implements the yaml.Marshaler interface.
+func (s NumericSize) MarshalYAML() (interface{}, error) {
+	return s.bytes, nil
+}
+
+// Format implements the fmt.Formatter interface as Size does, printing
+// GoString for the %#v verb.
+func (s NumericSize) Format(f fmt.State, verb rune) {
+	if verb == 'v' && f.Flag('#') {
+		io.WriteString(f, s.GoString())
+		return
+	}
+	s.Size.Format(f, verb)
+}
+
+// GoString implements the fmt.GoStringer interface.
+func (s NumericSize) GoString() string {
+	return "bytefmt.NumericSize{" + s.Size.GoString() + "}"
+}
+
+// Value implements the sql.Valuer interface. It always produces an int64.
+func (s NumericSize) Value() (driver.Value, error) {
+	return s.bytes, nil
+}
+
+// NullNumericSize is a nullable representation of NumericSize.
+type NullNumericSize struct{ NullSize }
+
+// MarshalJSON implements the json.Marshaler interface.
+func (s NullNumericSize) MarshalJSON() ([]byte, error) {
+	if !s.Valid {
+		return []byte("null"), nil
+	}
+	return NumericSize{s.Size}.MarshalJSON()
+}
+
+// MarshalText implements the encoding.TextMarshaler interface. A null size
+// produces empty text.
+func (s NullNumericSize) MarshalText() ([]byte, error) {
+	if !s.Valid {
+		return []byte{}, nil
+	}
+	return NumericSize{s.Size}.MarshalText()
+}
+
+// MarshalYAML implements the yaml.Marshaler interface.
+func (s NullNumericSize) MarshalYAML() (interface{}, error) {
+	if !s.Valid {
+		return nil, nil
+	}
+	return NumericSize{s.Size}.MarshalYAML()
+}
+
+// Format implements the fmt.Formatter interface as NullSize does, printing
+// GoString for the %#v verb.
+func (s NullNumericSize) Format(f fmt.State, verb rune) {
+	if verb == 'v' && f.Flag('#') {
+		io.WriteString(f, s.GoString())
+		return
+	}
+	s.NullSize.Format(f, verb)
+}
+
+// GoString implements the fmt.GoStringer interface.
+func (s NullNumericSize) GoString() string {
+	return "bytefmt.NullNumericSize{" + s.NullSize.GoString() + "}"
+}
+
+// Value implements the driver.Valuer interface.
+func (s NullNumericSize) Value() (driver.Value, error) {
+	if !s.Valid {
+		return nil, nil
+	}
+	return NumericSize{s.Size}.Value()
+}
+
+// DisplaySize is a Size which marshals to JSON as an object holding both its
+// exact count of bytes and a rounded form for display. It unmarshals from any
+// form accepted by Size. Only JSON differs; other encodings are the same as
+// Size's.
+//
+//    {"cache": {"bytes": 1610612736, "display": "1.5 GiB"}}
+type DisplaySize struct{ Size }
+
+// Format implements the fmt.Formatter interface as Size does, printing
+// GoString for the %#v verb.
+func (s DisplaySize) Format(f fmt.State, verb rune) {
+	if verb == 'v' && f.Flag('#') {
+		io.WriteString(f, s.GoString())
+		return
+	}
+	s.Size.Format(f, verb)
+}
+
+// GoString implements the fmt.GoStringer interface.
+func (s DisplaySize) GoString() string {
+	return "bytefmt.DisplaySize{" + s.Size.GoString() + "}"
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (s DisplaySize) MarshalJSON() ([]byte, error) {
+	result := make([]byte, 0, 64)
+	result = append(result, `{"bytes":`...)
+	result = strconv.AppendInt(result, s.bytes, 10)
+	result = append(result, `,"display":`...)
+	result = strconv.AppendQuote(result, fmt.Sprint(s.Size))
+	result = append(result, '}')
+	return result, nil
+}
+
+// unmarshalJSONObject decodes the form produced by DisplaySize. The byte count
+// is authoritative; the display string only determines the size's base.
+func (s *Size) unmarshalJSONObject(value []byte) error {
+	var obj struct {
+		Bytes   *json.Number `json:"bytes"`
+		Display string       `json:"display"`
+	}
+	if err := json.Unmarshal(value, &obj); err != nil {
+		return fmt.Errorf("can't decode %q as bytefmt.Size: %w", value, err)
+	}
+	if obj.Bytes == nil {
+		return fmt.Errorf("can't decode %q as bytefmt.Size: missing bytes", value)
+	}
+
+	bytes, err := parseJSONNumber([]byte(*obj.Bytes))
+	if err != nil {
+		return fmt.Errorf("can't decode %q as bytefmt.Size: %w", value, err)
+	}
+
+	base := Metric
+	if display, err := parse(obj.Display); err == nil {
+		base = display.Base
+	}
+	*s = *New(bytes, base)
+	return nil
+}
+
+// isJSONNumber returns whether a value is a syntactically valid JSON number.
+func isJSONNumber(value []byte) bool {
+	return len(value) != 0 && (value[0] == '-' || (value[0] >= '0' && value[0] <= '9')) &&
+		json.Valid(value)
+}
+
+// parseJSONNumber converts a JSON number to an exact count of bytes.
+func parseJSONNumber(value []byte) (int64, error) {
+	if !isJSONNumber(value) {
+		return 0, errors.New("not a string or number")
+	}
+
+	if bytes, err := strconv.ParseInt(string(value), 10, 64); err == nil {
+		return bytes, nil
+	}
+
+	// Fractions and exponents are accepted only if they amount to a whole number.
+	var r big.Rat
+	if _, ok := r.SetString(string(value)); !ok {
+		return 0, errors.New("not a number")
+	}
+	if !r.IsInt() {
+		return 0, fmt.Errorf("%s is not a whole number of bytes", value)
+	}
+	if !r.Num().IsInt64() {
+		return 0, errors.New("value exceeds 64 bits")
+	}
+	return r.Num().Int64(), nil
+}
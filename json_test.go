@@ -0,0 +1,153 @@
+package bytefmt
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		In        string
+		Expect    *Size
+		ExpectErr string
+	}{
+		// Strings
+		{In: `"1.5 GiB"`, Expect: New(1536*MiB, Binary)},
+		{In: `"1024"`, Expect: New(1024, Metric)},
+		{In: `""`, ExpectErr: `can't convert "" to size: empty string`},
+
+		// Numbers
+		{In: `0`, Expect: New(0, Metric)},
+		{In: `1048576`, Expect: New(MiB, Metric)},
+		{In: `-1`, Expect: New(-1, Metric)},
+		{In: `1e9`, Expect: New(GB, Metric)},
+		{In: `1.5E3`, Expect: New(1500, Metric)},
+		{In: `9223372036854775807`, Expect: New(math.MaxInt64, Metric)},
+		{In: `1.5`, ExpectErr: `can't decode "1.5" as bytefmt.Size: 1.5 is not a whole number of bytes`},
+		{In: `9223372036854775808`, ExpectErr: `can't decode "9223372036854775808" as bytefmt.Size: value exceeds 64 bits`},
+
+		// Objects
+		{In: `{"bytes":1610612736,"display":"1.5 GiB"}`, Expect: New(1536*MiB, Binary)},
+		{In: `{"bytes":1500000000,"display":"1.5 GB"}`, Expect: New(1500*MB, Metric)},
+		{In: `{"bytes":1500000000}`, Expect: New(1500*MB, Metric)},
+		{In: `{"display":"1.5 GiB"}`, ExpectErr: `can't decode "{\"display\":\"1.5 GiB\"}" as bytefmt.Size: missing bytes`},
+
+		// Other values
+		{In: `null`, ExpectErr: `can't decode null as bytefmt.Size`},
+		{In: `true`, ExpectErr: `can't decode "true" as bytefmt.Size: not a string or number`},
+		{In: `1kB`, ExpectErr: `can't decode "1kB" as bytefmt.Size: not a string or number`},
+	}
+
+	for _, test := range tests {
+		var size Size
+		err := size.UnmarshalJSON([]byte(test.In))
+
+		if test.ExpectErr != "" {
+			assertEqualErr(t, test.ExpectErr, err, "Error for %s", test.In)
+			continue
+		}
+		if assertNoErr(t, err, "Unexpected error for %s", test.In) {
+			assertEqual(t, *test.Expect, size, "Size for %s", test.In)
+		}
+	}
+}
+
+func TestJSONWrappers(t *testing.T) {
+	type response struct {
+		Default Size        `json:"default"`
+		Numeric NumericSize `json:"numeric"`
+		Display DisplaySize `json:"display"`
+	}
+
+	size := *New(1536*MiB, Binary)
+	in := response{Default: size, Numeric: NumericSize{size}, Display: DisplaySize{size}}
+
+	b, err := json.Marshal(in)
+	if !assertNoErr(t, err, "Marshalling %v", in) {
+		return
+	}
+	expect := `{"default":"1536 MiB","numeric":1610612736,` +
+		`"display":{"bytes":1610612736,"display":"1.5 GiB"}}`
+	assertEqual(t, expect, string(b), "JSON for %v", in)
+
+	var out response
+	if assertNoErr(t, json.Unmarshal(b, &out), "Unmarshalling %s", b) {
+		// Base is lost for plain numbers.
+		expect := in
+		expect.Numeric.Base = Metric
+		assertEqual(t, expect, out, "Round trip")
+	}
+
+	// All forms are accepted regardless of wrapper.
+	b = []byte(`{"default":1024,"numeric":"1 KiB","display":"1kB"}`)
+	if assertNoErr(t, json.Unmarshal(b, &out), "Unmarshalling %s", b) {
+		assertEqual(t, *New(1024, Metric), out.Default, "Default from number")
+		assertEqual(t, *New(KiB, Binary), out.Numeric.Size, "Numeric from string")
+		assertEqual(t, *New(KB, Metric), out.Display.Size, "Display from string")
+	}
+}
+
+func TestNumericValue(t *testing.T) {
+	size := *New(1536*MiB, Binary)
+
+	value, err := NumericSize{size}.Value()
+	assertNoErr(t, err, "Value of %v", size)
+	assertEqual(t, int64(1536*MiB), value, "Value of %v", size)
+
+	value, err = NullNumericSize{NullSize{Size: size, Valid: true}}.Value()
+	assertNoErr(t, err, "Value of %v", size)
+	assertEqual(t, int64(1536*MiB), value, "Value of %v", size)
+
+	value, err = NullNumericSize{}.Value()
+	assertNoErr(t, err, "Value of null")
+	assertEqual(t, nil, value, "Value of null")
+
+	var null NullNumericSize
+	if assertNoErr(t, null.Scan(int64(1024)), "Scanning 1024") {
+		assertEqual(t, NullNumericSize{NullSize{Size: *New(1024, Metric), Valid: true}}, null,
+			"Scanned 1024")
+	}
+
+	b, err := json.Marshal([]NullNumericSize{{}, null})
+	if assertNoErr(t, err, "Marshalling nullable sizes") {
+		assertEqual(t, "[null,1024]", string(b), "JSON for nullable sizes")
+	}
+}
+
+func TestNumericEncodings(t *testing.T) {
+	size := *New(1536*MiB, Binary)
+
+	text, err := NumericSize{size}.MarshalText()
+	assertNoErr(t, err, "Text for %v", size)
+	assertEqual(t, "1610612736", string(text), "Text for %v", size)
+
+	value, err := NumericSize{size}.MarshalYAML()
+	assertNoErr(t, err, "YAML for %v", size)
+	assertEqual(t, int64(1536*MiB), value, "YAML for %v", size)
+
+	var numeric NumericSize
+	if assertNoErr(t, numeric.UnmarshalText(text), "Unmarshalling %s", text) {
+		assertEqual(t, NumericSize{*New(1536*MiB, Metric)}, numeric, "Text round trip")
+	}
+
+	text, err = NullNumericSize{}.MarshalText()
+	assertNoErr(t, err, "Text for null")
+	assertEqual(t, "", string(text), "Text for null")
+
+	value, err = NullNumericSize{}.MarshalYAML()
+	assertNoErr(t, err, "YAML for null")
+	assertEqual(t, nil, value, "YAML for null")
+
+	value, err = NullNumericSize{NullSizeFrom(size)}.MarshalYAML()
+	assertNoErr(t, err, "YAML for %v", size)
+	assertEqual(t, int64(1536*MiB), value, "YAML for %v", size)
+
+	assertEqual(t, "bytefmt.NumericSize{*bytefmt.New(1536*bytefmt.MiB, bytefmt.Binary)}",
+		fmt.Sprintf("%#v", NumericSize{size}), "Numeric Go syntax")
+	assertEqual(t, "bytefmt.NullNumericSize{bytefmt.NullSize{}}",
+		fmt.Sprintf("%#v", NullNumericSize{}), "Null numeric Go syntax")
+	assertEqual(t, "bytefmt.DisplaySize{*bytefmt.New(1536*bytefmt.MiB, bytefmt.Binary)}",
+		fmt.Sprintf("%#v", DisplaySize{size}), "Display Go syntax")
+}
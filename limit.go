@@ -0,0 +1,200 @@
+package bytefmt
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultUnlimitedNames returns the spellings of an unbounded limit accepted by
+// ParseLimit. They follow the conventions of cgroups ("max"), ulimit
+// ("unlimited"), and systemd ("infinity").
+func DefaultUnlimitedNames() []string {
+	return []string{"unlimited", "max", "none", "infinity"}
+}
+
+// LimitParser parses and formats limits with a configurable set of spellings for
+// an unbounded limit. The zero value uses DefaultUnlimitedNames.
+//
+//    p := bytefmt.LimitParser{UnlimitedNames: []string{"-1", "unbounded"}}
+//    p.Parse("-1")       = NoLimit()
+//    p.Format(NoLimit()) = "-1"
+type LimitParser struct {
+	// UnlimitedNames are the spellings accepted as an unbounded limit. Matching
+	// is case-insensitive. The first name is used when formatting an unbounded
+	// limit.
+	UnlimitedNames []string
+}
+
+// Parse converts a string representation of a limit to a Limit. Any of the
+// parser's unlimited names produces an unbounded limit; all other values are
+// parsed as by Parse.
+func (p LimitParser) Parse(s string) (Limit, error) {
+	if p.unlimited(s) {
+		return NoLimit(), nil
+	}
+
+	size, err := parse(s)
+	if err != nil {
+		return Limit{}, fmt.Errorf("can't convert %q to limit: %w", s, err)
+	}
+	return NewLimit(*size), nil
+}
+
+// Format returns the formatted limit. Unbounded limits are formatted as the
+// first of the parser's unlimited names.
+func (p LimitParser) Format(l Limit) string {
+	if l.Unlimited {
+		return p.names()[0]
+	}
+	return l.Size.String()
+}
+
+// unlimited returns whether s is one of the parser's unlimited names.
+func (p LimitParser) unlimited(s string) bool {
+	for _, name := range p.names() {
+		if strings.EqualFold(s, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p LimitParser) names() []string {
+	if len(p.UnlimitedNames) == 0 {
+		return DefaultUnlimitedNames()
+	}
+	return p.UnlimitedNames
+}
+
+// Limit is an upper bound on a byte quantity which may be unbounded.
+type Limit struct {
+	Size Size
+
+	// Unlimited is true if the limit is unbounded, in which case Size is ignored.
+	Unlimited bool
+}
+
+// NewLimit returns a finite limit of the given size.
+func NewLimit(size Size) Limit { return Limit{Size: size} }
+
+// NoLimit returns an unbounded limit.
+func NoLimit() Limit { return Limit{Unlimited: true} }
+
+// Allows returns whether a size falls within the limit.
+func (l Limit) Allows(s Size) bool {
+	return l.Unlimited || s.Cmp(l.Size) <= 0
+}
+
+// Cmp compares l and y and returns:
+//   -1 if l <  y
+//    0 if l == y
+//   +1 if l >  y
+//
+// An unbounded limit is greater than all finite limits.
+func (l Limit) Cmp(y Limit) int {
+	switch {
+	case l.Unlimited && y.Unlimited:
+		return 0
+	case l.Unlimited:
+		return 1
+	case y.Unlimited:
+		return -1
+	default:
+		return l.Size.Cmp(y.Size)
+	}
+}
+
+// Equal returns whether two limits represent the same bound.
+func (l Limit) Equal(y Limit) bool { return l.Cmp(y) == 0 }
+
+// Min returns the more restrictive of l and y.
+func (l Limit) Min(y Limit) Limit {
+	if y.Cmp(l) < 0 {
+		return y
+	}
+	return l
+}
+
+// ParseLimit converts a string representation of a limit to a Limit. Any of
+// DefaultUnlimitedNames produces an unbounded limit; all other values are parsed
+// as by Parse. Use a LimitParser to accept other spellings.
+func ParseLimit(s string) (Limit, error) {
+	return LimitParser{}.Parse(s)
+}
+
+// String returns the formatted limit. Unbounded limits are formatted as
+// "unlimited".
+func (l Limit) String() string {
+	return LimitParser{}.Format(l)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (l Limit) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (l *Limit) UnmarshalText(value []byte) error {
+	limit, err := ParseLimit(string(value))
+	if err != nil {
+		return err
+	}
+	*l = limit
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (l Limit) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(l.String())), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It accepts strings
+// holding any of DefaultUnlimitedNames, and any value accepted by
+// Size.UnmarshalJSON.
+func (l *Limit) UnmarshalJSON(value []byte) error {
+	if string(value) == "null" {
+		return errors.New("can't decode null as bytefmt.Limit")
+	}
+
+	if len(value) != 0 && value[0] == '"' {
+		if str, err := strconv.Unquote(string(value)); err == nil && (LimitParser{}).unlimited(str) {
+			*l = NoLimit()
+			return nil
+		}
+	}
+
+	var size Size
+	if err := size.UnmarshalJSON(value); err != nil {
+		return err
+	}
+	*l = NewLimit(size)
+	return nil
+}
+
+// Value implements the sql.Valuer interface. It always produces a string.
+func (l Limit) Value() (driver.Value, error) {
+	return l.String(), nil
+}
+
+// Scan implements the sql.Scanner interface. It accepts string values and any
+// numeric value accepted by Size.Scan.
+func (l *Limit) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case string:
+		return l.UnmarshalText([]byte(v))
+
+	case []byte:
+		return l.UnmarshalText(v)
+
+	default:
+		var size Size
+		if err := size.Scan(value); err != nil {
+			return err
+		}
+		*l = NewLimit(size)
+		return nil
+	}
+}
@@ -0,0 +1,162 @@
+package bytefmt
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		In        string
+		Expect    Limit
+		ExpectErr string
+	}{
+		// Sentinels are case-insensitive.
+		{In: "unlimited", Expect: NoLimit()},
+		{In: "max", Expect: NoLimit()},
+		{In: "None", Expect: NoLimit()},
+		{In: "INFINITY", Expect: NoLimit()},
+
+		// Everything else is a size.
+		{In: "0", Expect: NewLimit(*New(0, Metric))},
+		{In: "4GiB", Expect: NewLimit(*New(4*GiB, Binary))},
+		{In: "-1", Expect: NewLimit(*New(-1, Metric))},
+
+		// Invalid values
+		{In: "", ExpectErr: "empty string"},
+		{In: "infinite", ExpectErr: "must start with a number"},
+	}
+
+	for _, test := range tests {
+		limit, err := ParseLimit(test.In)
+
+		if test.ExpectErr != "" {
+			expectErr := fmt.Sprintf("can't convert %q to limit: %s", test.In, test.ExpectErr)
+			assertEqualErr(t, expectErr, err, "Error for %q", test.In)
+			continue
+		}
+
+		if !assertNoErr(t, err, "Unxpected error for %q", test.In) {
+			continue
+		}
+		assertEqual(t, test.Expect, limit, "Limit for %q", test.In)
+	}
+}
+
+func TestLimitParser(t *testing.T) {
+	p := LimitParser{UnlimitedNames: []string{"-1", "Unbounded"}}
+
+	for _, in := range []string{"-1", "unbounded", "UNBOUNDED"} {
+		limit, err := p.Parse(in)
+		if assertNoErr(t, err, "Parsing %q", in) {
+			assertEqual(t, NoLimit(), limit, "Limit for %q", in)
+		}
+	}
+	_, err := p.Parse("max")
+	assertEqualErr(t, `can't convert "max" to limit: must start with a number`, err, "Parsing a default name")
+	limit, err := p.Parse("1 GiB")
+	if assertNoErr(t, err, "Parsing a size") {
+		assertEqual(t, NewLimit(*New(GiB, Binary)), limit, "Limit for a size")
+	}
+
+	assertEqual(t, "-1", p.Format(NoLimit()), "Formatted unbounded limit")
+	assertEqual(t, "1 GiB", p.Format(NewLimit(*New(GiB, Binary))), "Formatted finite limit")
+
+	// The zero value uses the defaults.
+	limit, err = LimitParser{}.Parse("infinity")
+	if assertNoErr(t, err, "Parsing with defaults") {
+		assertEqual(t, NoLimit(), limit, "Limit with defaults")
+	}
+	assertEqual(t, "unlimited", LimitParser{}.Format(NoLimit()), "Formatted with defaults")
+
+	// Modifying the defaults has no effect on other callers.
+	DefaultUnlimitedNames()[0] = "changed"
+	assertEqual(t, "unlimited", NoLimit().String(), "String after modifying defaults")
+}
+
+func TestLimitCompare(t *testing.T) {
+	small := NewLimit(*New(1*MiB, Binary))
+	large := NewLimit(*New(1*GiB, Binary))
+
+	assertEqual(t, -1, small.Cmp(large), "Comparing %v against %v", small, large)
+	assertEqual(t, 1, NoLimit().Cmp(large), "Comparing unlimited against %v", large)
+	assertEqual(t, -1, large.Cmp(NoLimit()), "Comparing %v against unlimited", large)
+	assertEqual(t, 0, NoLimit().Cmp(NoLimit()), "Comparing unlimited against unlimited")
+
+	assertEqual(t, small, small.Min(large), "Min of %v and %v", small, large)
+	assertEqual(t, small, large.Min(small), "Min of %v and %v", large, small)
+	assertEqual(t, large, NoLimit().Min(large), "Min of unlimited and %v", large)
+	assertEqual(t, NoLimit(), NoLimit().Min(NoLimit()), "Min of unlimited and unlimited")
+
+	assertEqual(t, true, small.Allows(*New(1*MiB, Metric)), "%v allows 1 MB", small)
+	assertEqual(t, true, small.Allows(*New(1*MiB, Binary)), "%v allows 1 MiB", small)
+	assertEqual(t, false, small.Allows(*New(1*MiB+1, Binary)), "%v allows 1 MiB + 1", small)
+	assertEqual(t, true, NoLimit().Allows(*New(1<<62, Binary)), "Unlimited allows 4 EiB")
+}
+
+func TestLimitMarshal(t *testing.T) {
+	type config struct {
+		Memory Limit `json:"memory"`
+		Disk   Limit `json:"disk"`
+	}
+
+	in := config{Memory: NoLimit(), Disk: NewLimit(*New(10*GiB, Binary))}
+	b, err := json.Marshal(in)
+	if !assertNoErr(t, err, "Marshalling %v", in) {
+		return
+	}
+	assertEqual(t, `{"memory":"unlimited","disk":"10 GiB"}`, string(b), "JSON for %v", in)
+
+	var out config
+	err = json.Unmarshal([]byte(`{"memory":"max","disk":"10GiB"}`), &out)
+	if assertNoErr(t, err, "Unmarshalling JSON") {
+		assertEqual(t, in, out, "Round trip")
+	}
+
+	// Values other than unlimited names are decoded as sizes.
+	tests := []struct {
+		In        string
+		Expect    Limit
+		ExpectErr string
+	}{
+		{In: `"Infinity"`, Expect: NoLimit()},
+		{In: `1e3`, Expect: NewLimit(*New(KB, Metric))},
+		{In: `1024`, Expect: NewLimit(*New(1024, Metric))},
+		{In: `"1024"`, Expect: NewLimit(*New(1024, Metric))},
+		{In: `""`, ExpectErr: `can't convert "" to size: empty string`},
+		{In: `1.5`, ExpectErr: `can't decode "1.5" as bytefmt.Size: 1.5 is not a whole number of bytes`},
+		{In: `null`, ExpectErr: `can't decode null as bytefmt.Limit`},
+	}
+	for _, test := range tests {
+		var limit Limit
+		err := json.Unmarshal([]byte(test.In), &limit)
+		if test.ExpectErr != "" {
+			assertEqualErr(t, test.ExpectErr, err, "Error for %s", test.In)
+			continue
+		}
+		if assertNoErr(t, err, "Unmarshalling %s", test.In) {
+			assertEqual(t, test.Expect, limit, "Limit for %s", test.In)
+		}
+	}
+
+	var scanned Limit
+	if assertNoErr(t, scanned.Scan("infinity"), "Scanning infinity") {
+		assertEqual(t, NoLimit(), scanned, "Scanned infinity")
+	}
+	if assertNoErr(t, scanned.Scan(int64(512)), "Scanning 512") {
+		assertEqual(t, NewLimit(*New(512, Metric)), scanned, "Scanned 512")
+	}
+	if assertNoErr(t, scanned.Scan(float64(1024)), "Scanning float 1024") {
+		assertEqual(t, NewLimit(*New(1024, Metric)), scanned, "Scanned float 1024")
+	}
+	if assertNoErr(t, scanned.Scan(uint32(2048)), "Scanning uint32 2048") {
+		assertEqual(t, NewLimit(*New(2048, Metric)), scanned, "Scanned uint32 2048")
+	}
+	assertEqualErr(t, "could not convert value '1.5' of type 'float64' to bytefmt.Size: "+
+		"1.5 is not a whole number of bytes", scanned.Scan(1.5), "Scanning a fraction")
+	value, err := NoLimit().Value()
+	if assertNoErr(t, err, "Value of unlimited") {
+		assertEqual(t, "unlimited", value, "Value of unlimited")
+	}
+}
@@ -0,0 +1,137 @@
+package bytefmt
+
+import (
+	"fmt"
+	"io"
+)
+
+// LimitExceededError reports an attempt to exceed a size limit.
+type LimitExceededError struct {
+	Limit Size
+
+	// Attempted is the size the rejected operation would have reached, in the
+	// limit's base. Readers can't know a stream's length, so they report the bytes
+	// seen, which is a lower bound: one byte beyond the limit with AllowExact, or
+	// the limit itself without.
+	Attempted Size
+}
+
+func (e *LimitExceededError) Error() string {
+	if e.Attempted.bytes <= e.Limit.bytes {
+		return fmt.Sprintf("%s reaches the limit of %s", e.Attempted.String(), e.Limit.String())
+	}
+	return fmt.Sprintf("%s exceeds the limit of %s", e.Attempted.String(), e.Limit.String())
+}
+
+func limitExceeded(limit Size, attempted int64) *LimitExceededError {
+	return &LimitExceededError{Limit: limit, Attempted: Size{bytes: attempted, Base: limit.Base}}
+}
+
+// LimitedReader reads from R, failing with a *LimitExceededError rather than
+// reading beyond Limit. Unlike io.LimitedReader, an overlong stream is an error
+// rather than being silently truncated.
+//
+//    body := bytefmt.LimitReader(req.Body, maxBody)
+//    body.AllowExact = true
+//    if _, err := io.Copy(dst, body); errors.As(err, new(*bytefmt.LimitExceededError)) {
+//        http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+//    }
+type LimitedReader struct {
+	R     io.Reader
+	Limit Size
+
+	// AllowExact determines whether a stream of exactly Limit bytes is allowed.
+	// If set, the reader reads one byte beyond the limit to detect the end of the
+	// stream, and that byte is discarded. Otherwise the reader never reads beyond
+	// the limit, so the read which reaches it fails even if the stream ends there.
+	AllowExact bool
+
+	n   int64
+	err error
+}
+
+// LimitReader returns a reader which fails after reading limit bytes from r.
+func LimitReader(r io.Reader, limit Size) *LimitedReader {
+	return &LimitedReader{R: r, Limit: limit}
+}
+
+// Read implements the io.Reader interface.
+func (l *LimitedReader) Read(p []byte) (int, error) {
+	if l.err != nil {
+		return 0, l.err
+	}
+
+	remaining := l.Limit.bytes - l.n
+	if remaining <= 0 {
+		if !l.AllowExact {
+			l.err = limitExceeded(l.Limit, l.n)
+			return 0, l.err
+		}
+		var b [1]byte
+		if n, err := io.ReadFull(l.R, b[:]); n == 0 {
+			return 0, err
+		}
+		l.err = limitExceeded(l.Limit, l.n+1)
+		return 0, l.err
+	}
+
+	if int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+	n, err := l.R.Read(p)
+	l.n += int64(n)
+	if !l.AllowExact && l.n >= l.Limit.bytes {
+		l.err = limitExceeded(l.Limit, l.n)
+		return n, l.err
+	}
+	return n, err
+}
+
+// Count returns the number of bytes read so far, in the limit's base.
+func (l *LimitedReader) Count() Size { return Size{bytes: l.n, Base: l.Limit.Base} }
+
+// LimitedWriter writes to W, failing with a *LimitExceededError rather than
+// writing beyond Limit. A write which would exceed the limit writes as much as
+// the limit allows before failing.
+type LimitedWriter struct {
+	W     io.Writer
+	Limit Size
+
+	n   int64
+	err error
+}
+
+// LimitWriter returns a writer which fails after writing limit bytes to w.
+func LimitWriter(w io.Writer, limit Size) *LimitedWriter {
+	return &LimitedWriter{W: w, Limit: limit}
+}
+
+// Write implements the io.Writer interface.
+func (l *LimitedWriter) Write(p []byte) (int, error) {
+	if l.err != nil {
+		return 0, l.err
+	}
+
+	remaining := l.Limit.bytes - l.n
+	if int64(len(p)) <= remaining {
+		n, err := l.W.Write(p)
+		l.n += int64(n)
+		return n, err
+	}
+
+	attempted := l.n + int64(len(p))
+	var n int
+	if remaining > 0 {
+		var err error
+		n, err = l.W.Write(p[:remaining])
+		l.n += int64(n)
+		if err != nil {
+			return n, err
+		}
+	}
+	l.err = limitExceeded(l.Limit, attempted)
+	return n, l.err
+}
+
+// Count returns the number of bytes written so far, in the limit's base.
+func (l *LimitedWriter) Count() Size { return Size{bytes: l.n, Base: l.Limit.Base} }
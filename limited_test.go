@@ -0,0 +1,92 @@
+package bytefmt
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestLimitReader(t *testing.T) {
+	tests := []struct {
+		In         string
+		Limit      int64
+		AllowExact bool
+		Expect     string
+		ExpectErr  string
+	}{
+		{In: "hello", Limit: 10, Expect: "hello"},
+		{In: "hello", Limit: 10, AllowExact: true, Expect: "hello"},
+		{In: "hello, world", Limit: 10, Expect: "hello, wor", ExpectErr: "10 B reaches the limit of 10 B"},
+		{In: "hello, world", Limit: 10, AllowExact: true, Expect: "hello, wor",
+			ExpectErr: "11 B exceeds the limit of 10 B"},
+		{In: "", Limit: 0, AllowExact: true, Expect: ""},
+		{In: "x", Limit: 0, Expect: "", ExpectErr: "0 B reaches the limit of 0 B"},
+		{In: "x", Limit: 0, AllowExact: true, Expect: "", ExpectErr: "1 B exceeds the limit of 0 B"},
+
+		// Without AllowExact, a stream which reaches the limit fails even if it ends
+		// there.
+		{In: "0123456789", Limit: 10, Expect: "0123456789", ExpectErr: "10 B reaches the limit of 10 B"},
+		{In: "0123456789", Limit: 10, AllowExact: true, Expect: "0123456789"},
+	}
+
+	// The result mustn't depend on how the underlying reader reports its end.
+	readers := map[string]func(io.Reader) io.Reader{
+		"OneByteReader": iotest.OneByteReader,
+		"DataErrReader": iotest.DataErrReader,
+		"Reader":        func(r io.Reader) io.Reader { return r },
+	}
+
+	for _, test := range tests {
+		for name, wrap := range readers {
+			r := LimitReader(wrap(strings.NewReader(test.In)), *New(test.Limit, Binary))
+			r.AllowExact = test.AllowExact
+			out, err := ioutil.ReadAll(r)
+
+			assertEqual(t, test.Expect, string(out), "Read from %q with %s (limit=%d, exact=%v)",
+				test.In, name, test.Limit, test.AllowExact)
+			assertEqual(t, *New(int64(len(out)), Binary), r.Count(), "Count of %q with %s", test.In, name)
+			if test.ExpectErr == "" {
+				assertNoErr(t, err, "Reading %q with %s", test.In, name)
+				continue
+			}
+			assertEqualErr(t, test.ExpectErr, err, "Error reading %q with %s", test.In, name)
+
+			// The error is sticky.
+			_, again := r.Read(make([]byte, 1))
+			assertEqual(t, err, again, "Error after failure reading %q with %s", test.In, name)
+		}
+	}
+}
+
+func TestLimitWriter(t *testing.T) {
+	var out bytes.Buffer
+	w := LimitWriter(&out, *New(MiB, Binary))
+
+	_, err := w.Write(make([]byte, 1000*KiB))
+	assertNoErr(t, err, "Writing under the limit")
+
+	n, err := w.Write(make([]byte, 32*KiB))
+	assertEqual(t, int(24*KiB), n, "Bytes written before exceeding the limit")
+	assertEqualErr(t, "1032 KiB exceeds the limit of 1 MiB", err, "Error writing over the limit")
+	assertEqual(t, int(MiB), out.Len(), "Total written")
+	assertEqual(t, *New(MiB, Binary), w.Count(), "Count")
+
+	var limitErr *LimitExceededError
+	if assertEqual(t, true, errors.As(err, &limitErr), "Error type") {
+		assertEqual(t, *New(MiB, Binary), limitErr.Limit, "Limit")
+		assertEqual(t, *New(1032*KiB, Binary), limitErr.Attempted, "Attempted")
+	}
+
+	n, err = w.Write([]byte("x"))
+	assertEqual(t, 0, n, "Bytes written after failure")
+	assertEqual(t, limitErr, err, "Error after failure")
+
+	// Copying exactly the limit is allowed.
+	w = LimitWriter(ioutil.Discard, *New(4*KB, Metric))
+	_, err = io.Copy(w, strings.NewReader(strings.Repeat("x", 4000)))
+	assertNoErr(t, err, "Copying exactly the limit")
+}
@@ -0,0 +1,276 @@
+// Package progress tracks the bytes transferred by readers and writers,
+// estimating throughput and time remaining, and renders reports of them.
+package progress
+
+import (
+	"io"
+	"math"
+	"strconv"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/allenai/bytefmt"
+)
+
+// Progress tracks the bytes transferred by a reader or writer over time,
+// estimating throughput and time remaining. The zero value is ready to use.
+//
+//    p := &progress.Progress{Total: bytefmt.NullSizeFrom(size), Base: bytefmt.Binary}
+//    r, _ := progress.NewRenderer(os.Stderr, "\r"+progress.DefaultTemplate)
+//    p.OnUpdate = r.Render
+//    io.Copy(dst, p.Reader(src))
+//    p.Finish()
+//
+// Progress is safe for concurrent use.
+type Progress struct {
+	// Total is the expected number of bytes, if known.
+	Total bytefmt.NullSize
+
+	// Base determines how reported sizes are formatted.
+	Base bytefmt.Base
+
+	// Interval is the minimum time between samples of the transfer rate, each of
+	// which is reported to OnUpdate. It defaults to one second.
+	Interval time.Duration
+
+	// HalfLife determines how quickly the reported rate follows changes in
+	// throughput: a sample's weight in the rate halves with each HalfLife that
+	// passes. It defaults to five seconds.
+	HalfLife time.Duration
+
+	// OnUpdate, if set, is called with a report after each sample and when the
+	// transfer finishes. It's called synchronously and must not call methods of
+	// the Progress.
+	OnUpdate func(Report)
+
+	// Clock provides the time, defaulting to the system clock.
+	Clock bytefmt.Clock
+
+	mu       sync.Mutex
+	started  bool
+	start    time.Time
+	n        int64
+	last     time.Time // Time of the last sample
+	lastN    int64     // Bytes transferred at the last sample
+	rate     float64   // Smoothed bytes per second
+	sampled  bool
+	finished bool
+}
+
+// Report is a snapshot of a transfer's progress.
+type Report struct {
+	// Current is the number of bytes transferred so far.
+	Current bytefmt.Size
+
+	// Total is the expected number of bytes, if known.
+	Total bytefmt.NullSize
+
+	// Elapsed is the time since the transfer began.
+	Elapsed time.Duration
+
+	// Rate is the smoothed number of bytes transferred per second.
+	Rate bytefmt.Size
+
+	// ETA is the estimated time remaining, rounded to the second. It's zero if
+	// the transfer is complete or the time remaining is unknown.
+	ETA time.Duration
+
+	// Done is set in the report made by Finish.
+	Done bool
+}
+
+// Percent returns the percentage of the total transferred, or zero if the total
+// is unknown or zero.
+func (r Report) Percent() float64 {
+	if !r.Total.Valid || r.Total.Size.IsZero() {
+		return 0
+	}
+	return float64(r.Current.Int64()) / float64(r.Total.Size.Int64()) * 100
+}
+
+// String formats the report as DefaultTemplate does.
+func (r Report) String() string {
+	b := make([]byte, 0, 64)
+	b = r.Current.AppendFormat(b, 'g', 4)
+	if r.Total.Valid {
+		b = append(b, " / "...)
+		b = r.Total.Size.AppendFormat(b, 'g', 4)
+		b = append(b, " ("...)
+		b = strconv.AppendFloat(b, r.Percent(), 'f', 0, 64)
+		b = append(b, "%)"...)
+	}
+	b = append(b, ' ')
+	b = r.Rate.AppendFormat(b, 'g', 4)
+	b = append(b, "/s"...)
+	if r.ETA != 0 {
+		b = append(b, " ETA "...)
+		b = append(b, r.ETA.String()...)
+	}
+	return string(b)
+}
+
+// Add records n bytes as transferred.
+func (p *Progress) Add(n int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	p.n += n
+	if now.Sub(p.last) >= p.interval() && p.sample(now) && p.OnUpdate != nil {
+		p.OnUpdate(p.report(now))
+	}
+}
+
+// Reader returns a reader which records bytes read from r.
+func (p *Progress) Reader(r io.Reader) io.Reader { return progressReader{p, r} }
+
+// Writer returns a writer which records bytes written to w.
+func (p *Progress) Writer(w io.Writer) io.Writer { return progressWriter{p, w} }
+
+// Report returns the transfer's current progress.
+func (p *Progress) Report() Report {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.report(p.now())
+}
+
+// Finish marks the transfer complete, reporting its final progress to OnUpdate.
+// Only the first call has any effect.
+func (p *Progress) Finish() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.finished {
+		return
+	}
+	now := p.now()
+	p.sample(now)
+	p.finished = true
+	if p.OnUpdate != nil {
+		p.OnUpdate(p.report(now))
+	}
+}
+
+// now returns the current time, starting the transfer if it hasn't already.
+func (p *Progress) now() time.Time {
+	var now time.Time
+	if p.Clock != nil {
+		now = p.Clock.Now()
+	} else {
+		now = time.Now()
+	}
+	if !p.started {
+		p.started = true
+		p.start, p.last = now, now
+	}
+	return now
+}
+
+func (p *Progress) interval() time.Duration {
+	if p.Interval <= 0 {
+		return time.Second
+	}
+	return p.Interval
+}
+
+// sample updates the rate with bytes transferred since the last sample. It
+// returns false if no time has passed.
+func (p *Progress) sample(now time.Time) bool {
+	dt := now.Sub(p.last)
+	if dt <= 0 {
+		return false
+	}
+
+	rate := float64(p.n-p.lastN) / dt.Seconds()
+	if p.sampled {
+		halfLife := p.HalfLife
+		if halfLife <= 0 {
+			halfLife = 5 * time.Second
+		}
+		weight := 1 - math.Exp2(-dt.Seconds()/halfLife.Seconds())
+		rate = p.rate + weight*(rate-p.rate)
+	}
+
+	p.rate, p.sampled = rate, true
+	p.last, p.lastN = now, p.n
+	return true
+}
+
+func (p *Progress) report(now time.Time) Report {
+	r := Report{
+		Current: *bytefmt.New(p.n, p.Base),
+		Total:   p.Total,
+		Elapsed: now.Sub(p.start),
+		Rate:    *bytefmt.New(int64(math.Round(p.rate)), p.Base),
+		Done:    p.finished,
+	}
+	if p.Total.Valid {
+		r.Total.Size.Base = p.Base
+		if remaining := p.Total.Size.Int64() - p.n; remaining > 0 && p.rate > 0 && !p.finished {
+			eta := time.Duration(float64(remaining) / p.rate * float64(time.Second))
+			r.ETA = eta.Round(time.Second)
+		}
+	}
+	return r
+}
+
+type progressReader struct {
+	p *Progress
+	r io.Reader
+}
+
+func (r progressReader) Read(b []byte) (int, error) {
+	n, err := r.r.Read(b)
+	r.p.Add(int64(n))
+	return n, err
+}
+
+type progressWriter struct {
+	p *Progress
+	w io.Writer
+}
+
+func (w progressWriter) Write(b []byte) (int, error) {
+	n, err := w.w.Write(b)
+	w.p.Add(int64(n))
+	return n, err
+}
+
+// DefaultTemplate formats a report as a single line, such as
+// "312 MiB / 1.2 GiB (26%) 45 MiB/s ETA 20s". Sizes are formatted by
+// bytefmt.Size.Format with the 'v' verb.
+const DefaultTemplate = `{{.Current}}` +
+	`{{if .Total.Valid}} / {{.Total}} ({{printf "%.0f" .Percent}}%){{end}}` +
+	` {{.Rate}}/s` +
+	`{{if .ETA}} ETA {{.ETA}}{{end}}`
+
+// Renderer writes progress reports to a writer using a text/template, whose data
+// is a Report. Its Render method may be used as Progress.OnUpdate.
+type Renderer struct {
+	w    io.Writer
+	tmpl *template.Template
+	err  error
+}
+
+// NewRenderer returns a renderer which writes reports to w using the template
+// text. The template typically begins with "\r" to overwrite the previous line
+// of a terminal.
+func NewRenderer(w io.Writer, text string) (*Renderer, error) {
+	tmpl, err := template.New("progress").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return &Renderer{w: w, tmpl: tmpl}, nil
+}
+
+// Render writes a report. Errors are retained and returned by Err; once one
+// occurs, nothing further is written.
+func (r *Renderer) Render(report Report) {
+	if r.err == nil {
+		r.err = r.tmpl.Execute(r.w, report)
+	}
+}
+
+// Err returns the first error encountered while rendering.
+func (r *Renderer) Err() error { return r.err }
@@ -0,0 +1,192 @@
+package progress
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/allenai/bytefmt"
+)
+
+// fakeClock is a manually advanced clock.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestProgress(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	var reports []Report
+	p := &Progress{
+		Total:    bytefmt.NullSizeFrom(*bytefmt.New(100*bytefmt.MiB, bytefmt.Binary)),
+		Base:     bytefmt.Binary,
+		Clock:    clock,
+		OnUpdate: func(r Report) { reports = append(reports, r) },
+	}
+
+	// Samples are taken at most once per interval.
+	p.Add(0)
+	clock.Advance(500 * time.Millisecond)
+	p.Add(5 * bytefmt.MiB)
+	assertEqual(t, 0, len(reports), "Reports before the first interval")
+
+	clock.Advance(500 * time.Millisecond)
+	p.Add(5 * bytefmt.MiB)
+	if assertEqual(t, 1, len(reports), "Reports after the first interval") {
+		assertEqual(t, Report{
+			Current: *bytefmt.New(10*bytefmt.MiB, bytefmt.Binary),
+			Total:   bytefmt.NullSizeFrom(*bytefmt.New(100*bytefmt.MiB, bytefmt.Binary)),
+			Elapsed: time.Second,
+			Rate:    *bytefmt.New(10*bytefmt.MiB, bytefmt.Binary),
+			ETA:     9 * time.Second,
+		}, reports[0], "First report")
+		assertEqual(t, "10 MiB / 100 MiB (10%) 10 MiB/s ETA 9s", reports[0].String(), "First report text")
+	}
+
+	// After one half-life, the rate moves halfway to the new throughput.
+	clock.Advance(5 * time.Second)
+	p.Add(20 * bytefmt.MiB)
+	if assertEqual(t, 2, len(reports), "Reports after the second interval") {
+		assertEqual(t, *bytefmt.New(7*bytefmt.MiB, bytefmt.Binary), reports[1].Rate, "Smoothed rate")
+		assertEqual(t, 10*time.Second, reports[1].ETA, "ETA")
+		assertEqual(t, 30.0, reports[1].Percent(), "Percent")
+	}
+
+	clock.Advance(3 * time.Second)
+	assertEqual(t, *bytefmt.New(30*bytefmt.MiB, bytefmt.Binary), p.Report().Current, "Current between samples")
+	assertEqual(t, 9*time.Second, p.Report().Elapsed, "Elapsed between samples")
+	assertEqual(t, 2, len(reports), "Reports after calling Report")
+
+	p.Finish()
+	p.Finish()
+	if assertEqual(t, 3, len(reports), "Reports after finishing") {
+		assertEqual(t, true, reports[2].Done, "Done")
+		assertEqual(t, time.Duration(0), reports[2].ETA, "ETA after finishing")
+	}
+}
+
+func TestProgressReaderWriter(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	p := &Progress{Clock: clock, Interval: time.Nanosecond}
+
+	var out bytes.Buffer
+	_, err := io.Copy(p.Writer(&out), p.Reader(strings.NewReader("hello")))
+	assertNoErr(t, err, "Copying")
+	assertEqual(t, int64(10), p.Report().Current.Int64(), "Bytes read and written")
+
+	// Without a total, neither percentage nor ETA are known.
+	clock.Advance(time.Second)
+	p.Add(990)
+	report := p.Report()
+	assertEqual(t, 0.0, report.Percent(), "Percent without total")
+	assertEqual(t, "1 kB 1 kB/s", report.String(), "Report text without total")
+}
+
+func TestRenderer(t *testing.T) {
+	var out bytes.Buffer
+	r, err := NewRenderer(&out, "\r"+DefaultTemplate)
+	if !assertNoErr(t, err, "Creating renderer") {
+		return
+	}
+
+	r.Render(Report{
+		Current: *bytefmt.New(312*bytefmt.MiB, bytefmt.Binary),
+		Total:   bytefmt.NullSizeFrom(*bytefmt.New(1200*bytefmt.MiB, bytefmt.Binary)),
+		Rate:    *bytefmt.New(45*bytefmt.MiB, bytefmt.Binary),
+		ETA:     20 * time.Second,
+	})
+	r.Render(Report{Current: *bytefmt.New(1500, bytefmt.Metric), Rate: *bytefmt.New(1024, bytefmt.Binary), Done: true})
+	assertNoErr(t, r.Err(), "Rendering")
+	assertEqual(t, "\r312 MiB / 1.172 GiB (26%) 45 MiB/s ETA 20s\r1.5 kB 1 KiB/s", out.String(), "Rendered")
+
+	// Report.String matches the default template without parsing it.
+	for _, report := range []Report{
+		{Current: *bytefmt.New(312*bytefmt.MiB, bytefmt.Binary), Total: bytefmt.NullSizeFrom(*bytefmt.New(1200*bytefmt.MiB, bytefmt.Binary)),
+			Rate: *bytefmt.New(45*bytefmt.MiB, bytefmt.Binary), ETA: 20 * time.Second},
+		{Current: *bytefmt.New(1500, bytefmt.Metric), Total: bytefmt.NullSizeFrom(bytefmt.Size{}), Rate: *bytefmt.New(1024, bytefmt.Binary)},
+		{Current: *bytefmt.New(999999, bytefmt.Metric), Rate: bytefmt.Size{}, ETA: 90*time.Minute + time.Second},
+	} {
+		out.Reset()
+		r.Render(report)
+		assertEqual(t, strings.TrimPrefix(out.String(), "\r"), report.String(), "String of %+v", report)
+	}
+
+	r, err = NewRenderer(&out, "{{.Elapsed}} elapsed{{if .Done}}, done{{end}}\n")
+	if assertNoErr(t, err, "Creating custom renderer") {
+		out.Reset()
+		r.Render(Report{Elapsed: 90 * time.Second, Done: true})
+		assertEqual(t, "1m30s elapsed, done\n", out.String(), "Rendered custom template")
+	}
+
+	r, _ = NewRenderer(errWriter{}, DefaultTemplate)
+	r.Render(Report{})
+	r.Render(Report{})
+	assertEqualErr(t, "write failed", r.Err(), "Render error")
+
+	_, err = NewRenderer(ioutil.Discard, "{{.Current")
+	assertEqualErr(t, `template: progress:1: unclosed action`, err, "Invalid template")
+}
+
+type errWriter struct{}
+
+func (errWriter) Write([]byte) (int, error) { return 0, errors.New("write failed") }
+
+func assertNoErr(t *testing.T, err error, message string, args ...interface{}) bool {
+	t.Helper()
+	if err == nil {
+		return true
+	}
+	t.Error(fmt.Sprintf(message, args...),
+		"\n    Error:", err)
+	return false
+}
+
+func assertEqualErr(
+	t *testing.T,
+	expect string,
+	actual error,
+	message string,
+	args ...interface{},
+) bool {
+	t.Helper()
+	if actual != nil {
+		return assertEqual(t, expect, actual.Error(), message, args...)
+	}
+	return assertEqual(t, expect, actual, message, args...)
+}
+
+func assertEqual(
+	t *testing.T,
+	expect interface{},
+	actual interface{},
+	message string,
+	args ...interface{},
+) bool {
+	t.Helper()
+	if reflect.DeepEqual(expect, actual) {
+		return true
+	}
+	t.Error(fmt.Sprintf(message, args...),
+		"\n    Expected:", expect,
+		"\n    Actual:  ", actual)
+	return false
+}
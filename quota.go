@@ -0,0 +1,278 @@
+package bytefmt
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// QuotaError records a quota whose limit would be exceeded.
+type QuotaError struct {
+	// Path identifies the quota whose limit was hit, such as "node/tenant-a".
+	Path string
+	Err  *LimitExceededError
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("quota %s: %v", e.Path, e.Err)
+}
+
+func (e *QuotaError) Unwrap() error { return e.Err }
+
+// Quota is a node in a tree of limits, such as a disk divided among tenants and
+// then among each tenant's jobs. Space used by a quota also counts against each
+// of its ancestors, so a reservation must fit within every limit from the quota
+// to the root. Committed space is released from the quota it was committed to,
+// rather than from an ancestor.
+//
+//    disk := bytefmt.NewQuota("node", bytefmt.NewLimit(*bytefmt.New(4*bytefmt.TiB, bytefmt.Binary)))
+//    tenant, _ := disk.NewChild("tenant-a", bytefmt.NewLimit(*bytefmt.New(bytefmt.TiB, bytefmt.Binary)))
+//    job, _ := tenant.NewChild("job-1", bytefmt.NoLimit())
+//
+//    r, err := job.Reserve(size)
+//    if err != nil {
+//        return err // A *QuotaError naming the exhausted quota
+//    }
+//    if err := write(); err != nil {
+//        r.Release()
+//        return err
+//    }
+//    r.Commit()
+//
+// Quotas in a tree are safe for concurrent use.
+type Quota struct {
+	mu       *sync.Mutex // Shared by the whole tree
+	name     string
+	limit    Limit
+	parent   *Quota
+	children []*Quota
+
+	used      int64 // Committed, including descendants
+	committed int64 // Committed to this quota alone, and so releasable from it
+	reserved  int64 // Reserved but not committed, including descendants
+}
+
+// NewQuota returns the root of a quota tree.
+func NewQuota(name string, limit Limit) *Quota {
+	return &Quota{mu: &sync.Mutex{}, name: name, limit: limit}
+}
+
+// NewChild adds a quota beneath q. It returns an error if q already has a child
+// with the same name, or if the name contains a slash.
+func (q *Quota) NewChild(name string, limit Limit) (*Quota, error) {
+	if strings.Contains(name, "/") {
+		return nil, fmt.Errorf("quota name %q can't contain a slash", name)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, c := range q.children {
+		if c.name == name {
+			return nil, fmt.Errorf("quota %s already has a child named %q", q.path(), name)
+		}
+	}
+
+	child := &Quota{mu: q.mu, name: name, limit: limit, parent: q}
+	q.children = append(q.children, child)
+	return child, nil
+}
+
+// Name returns the quota's name.
+func (q *Quota) Name() string { return q.name }
+
+// Path returns the names of the quota and its ancestors from the root, separated
+// by slashes.
+func (q *Quota) Path() string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.path()
+}
+
+func (q *Quota) path() string {
+	if q.parent == nil {
+		return q.name
+	}
+	return q.parent.path() + "/" + q.name
+}
+
+// Limit returns the quota's limit.
+func (q *Quota) Limit() Limit {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.limit
+}
+
+// SetLimit changes the quota's limit. Space already reserved or used isn't
+// affected, even if it exceeds the new limit.
+func (q *Quota) SetLimit(limit Limit) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.limit = limit
+}
+
+// Reserve sets aside n bytes of the quota to be committed or released later. It
+// fails with a *QuotaError naming the nearest quota, starting from q, whose limit
+// the reservation would exceed, or with an ordinary error if n is negative.
+func (q *Quota) Reserve(n Size) (*Reservation, error) {
+	if n.bytes < 0 {
+		return nil, fmt.Errorf("can't reserve a negative size of %s", n.String())
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for node := q; node != nil; node = node.parent {
+		if node.limit.Unlimited {
+			continue
+		}
+		attempted := node.used + node.reserved + n.bytes
+		if attempted > node.limit.Size.bytes {
+			return nil, &QuotaError{Path: node.path(), Err: limitExceeded(node.limit.Size, attempted)}
+		}
+	}
+
+	for node := q; node != nil; node = node.parent {
+		node.reserved += n.bytes
+	}
+	return &Reservation{quota: q, n: n.bytes}, nil
+}
+
+// Release returns n bytes of committed space to the quota and its ancestors. It
+// panics if n is negative or more is released than was committed to q itself;
+// space committed to a descendant must be released from that descendant.
+func (q *Quota) Release(n Size) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if n.bytes < 0 {
+		panic("bytefmt: released negative space to quota " + q.path())
+	}
+	if n.bytes > q.committed {
+		panic("bytefmt: released more than was committed to quota " + q.path())
+	}
+	q.committed -= n.bytes
+	for node := q; node != nil; node = node.parent {
+		node.used -= n.bytes
+	}
+}
+
+// Used returns the space committed to the quota and its descendants.
+func (q *Quota) Used() Size {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.size(q.used)
+}
+
+// Reserved returns the space reserved but not yet committed by the quota and its
+// descendants.
+func (q *Quota) Reserved() Size {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.size(q.reserved)
+}
+
+// size returns a count of bytes in the base of the nearest limit to the quota,
+// since unbounded limits have no base.
+func (q *Quota) size(n int64) Size {
+	for node := q; node != nil; node = node.parent {
+		if !node.limit.Unlimited {
+			return Size{bytes: n, Base: node.limit.Size.Base}
+		}
+	}
+	return Size{bytes: n}
+}
+
+// Reservation is space set aside in a quota. Each reservation must be either
+// committed or released; after that, further calls have no effect.
+type Reservation struct {
+	quota *Quota
+	n     int64
+	done  bool
+}
+
+// Size returns the space reserved.
+func (r *Reservation) Size() Size {
+	r.quota.mu.Lock()
+	defer r.quota.mu.Unlock()
+	return r.quota.size(r.n)
+}
+
+// Commit converts the reservation into space used by its quota.
+func (r *Reservation) Commit() {
+	r.quota.mu.Lock()
+	defer r.quota.mu.Unlock()
+	if r.done {
+		return
+	}
+	r.done = true
+	r.quota.committed += r.n
+	for node := r.quota; node != nil; node = node.parent {
+		node.reserved -= r.n
+		node.used += r.n
+	}
+}
+
+// Release returns the reserved space to its quota without using it.
+func (r *Reservation) Release() {
+	r.quota.mu.Lock()
+	defer r.quota.mu.Unlock()
+	if r.done {
+		return
+	}
+	r.done = true
+	for node := r.quota; node != nil; node = node.parent {
+		node.reserved -= r.n
+	}
+}
+
+// QuotaReport is a snapshot of a quota and its descendants.
+type QuotaReport struct {
+	Name     string
+	Limit    Limit
+	Used     Size
+	Reserved Size
+	Children []QuotaReport
+}
+
+// Snapshot returns a consistent report of the quota and its descendants.
+func (q *Quota) Snapshot() QuotaReport {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.snapshot()
+}
+
+func (q *Quota) snapshot() QuotaReport {
+	r := QuotaReport{
+		Name:     q.name,
+		Limit:    q.limit,
+		Used:     q.size(q.used),
+		Reserved: q.size(q.reserved),
+	}
+	for _, c := range q.children {
+		r.Children = append(r.Children, c.snapshot())
+	}
+	return r
+}
+
+// String formats the report as an indented tree with one quota per line. Sizes
+// are formatted by Size.Format with the 'v' verb.
+//
+//    node: 1.5 TiB used, 200 GiB reserved of 4 TiB
+//      tenant-a: 1.2 TiB used, 0 B reserved of 2 TiB
+//        job-1: 1.2 TiB used, 0 B reserved of unlimited
+func (r QuotaReport) String() string {
+	var b strings.Builder
+	r.write(&b, 0)
+	return b.String()
+}
+
+func (r QuotaReport) write(b *strings.Builder, depth int) {
+	limit := r.Limit.String()
+	if !r.Limit.Unlimited {
+		limit = fmt.Sprintf("%v", r.Limit.Size)
+	}
+	fmt.Fprintf(b, "%s%s: %v used, %v reserved of %s\n",
+		strings.Repeat("  ", depth), r.Name, r.Used, r.Reserved, limit)
+	for _, c := range r.Children {
+		c.write(b, depth+1)
+	}
+}
@@ -0,0 +1,117 @@
+package bytefmt
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestQuota(t *testing.T) {
+	node := NewQuota("node", NewLimit(*New(4*TiB, Binary)))
+	tenantA, err := node.NewChild("tenant-a", NewLimit(*New(2*TiB, Binary)))
+	assertNoErr(t, err, "Adding tenant-a")
+	tenantB, err := node.NewChild("tenant-b", NewLimit(*New(3*TiB, Binary)))
+	assertNoErr(t, err, "Adding tenant-b")
+	job, err := tenantA.NewChild("job-1", NoLimit())
+	assertNoErr(t, err, "Adding job-1")
+	assertEqual(t, "node/tenant-a/job-1", job.Path(), "Path")
+
+	// Reservations count against every ancestor.
+	r1, err := job.Reserve(*New(1536*GiB, Binary))
+	if !assertNoErr(t, err, "Reserving for job") {
+		return
+	}
+	assertEqual(t, *New(1536*GiB, Binary), r1.Size(), "Reservation size")
+	assertEqual(t, *New(1536*GiB, Binary), node.Reserved(), "Reserved by node")
+	assertEqual(t, *New(1536*GiB, Binary), tenantA.Reserved(), "Reserved by tenant")
+
+	// The nearest exhausted limit is reported.
+	_, err = job.Reserve(*New(600*GiB, Binary))
+	assertEqualErr(t, "quota node/tenant-a: 2136 GiB exceeds the limit of 2 TiB", err, "Exceeding the tenant")
+	var quotaErr *QuotaError
+	if assertEqual(t, true, errors.As(err, &quotaErr), "Error type") {
+		assertEqual(t, "node/tenant-a", quotaErr.Path, "Exhausted quota")
+	}
+	var limitErr *LimitExceededError
+	assertEqual(t, true, errors.As(err, &limitErr), "Wrapped error type")
+
+	r2, err := tenantB.Reserve(*New(2*TiB, Binary))
+	assertNoErr(t, err, "Reserving for tenant-b")
+	_, err = tenantB.Reserve(*New(600*GiB, Binary))
+	assertEqualErr(t, "quota node: 4184 GiB exceeds the limit of 4 TiB", err, "Exceeding the node")
+
+	// Committing moves reserved space to used; releasing returns it.
+	r1.Commit()
+	r1.Commit()
+	r1.Release()
+	r2.Release()
+	assertEqual(t, *New(1536*GiB, Binary), node.Used(), "Used by node")
+	assertEqual(t, *New(0, Binary), node.Reserved(), "Reserved by node after commit")
+	assertEqual(t, *New(1536*GiB, Binary), tenantA.Used(), "Used by tenant")
+
+	job.Release(*New(512*GiB, Binary))
+	assertEqual(t, *New(TiB, Binary), node.Used(), "Used by node after release")
+	assertEqual(t, *New(TiB, Binary), job.Used(), "Used by job after release")
+
+	// Lowering a limit affects only new reservations.
+	tenantA.SetLimit(NewLimit(*New(512*GiB, Binary)))
+	_, err = job.Reserve(*New(1, Binary))
+	assertEqualErr(t, "quota node/tenant-a: 1099511627777 B exceeds the limit of 512 GiB", err,
+		"Exceeding a lowered limit")
+	assertEqual(t, NewLimit(*New(512*GiB, Binary)), tenantA.Limit(), "Lowered limit")
+
+	// Negative sizes are rejected.
+	_, err = job.Reserve(*New(-GiB, Binary))
+	assertEqualErr(t, "can't reserve a negative size of -1 GiB", err, "Reserving a negative size")
+	func() {
+		defer func() {
+			assertEqual(t, "bytefmt: released negative space to quota node/tenant-a/job-1", recover(),
+				"Panic")
+		}()
+		job.Release(*New(-GiB, Binary))
+	}()
+
+	// Space committed to a descendant can't be released from an ancestor.
+	func() {
+		defer func() {
+			assertEqual(t, "bytefmt: released more than was committed to quota node/tenant-a", recover(),
+				"Panic")
+		}()
+		tenantA.Release(*New(GiB, Binary))
+	}()
+	assertEqual(t, *New(TiB, Binary), job.Used(), "Used by job after failed release")
+
+	defer func() {
+		assertEqual(t, "bytefmt: released more than was committed to quota node/tenant-b", recover(),
+			"Panic")
+	}()
+	tenantB.Release(*New(1, Binary))
+}
+
+func TestQuotaNames(t *testing.T) {
+	root := NewQuota("root", NoLimit())
+	_, err := root.NewChild("a", NoLimit())
+	assertNoErr(t, err, "Adding child")
+	_, err = root.NewChild("a", NoLimit())
+	assertEqualErr(t, `quota root already has a child named "a"`, err, "Duplicate name")
+	_, err = root.NewChild("a/b", NoLimit())
+	assertEqualErr(t, `quota name "a/b" can't contain a slash`, err, "Name with slash")
+}
+
+func TestQuotaSnapshot(t *testing.T) {
+	node := NewQuota("node", NewLimit(*New(4*TiB, Binary)))
+	tenant, _ := node.NewChild("tenant-a", NewLimit(*New(2*TiB, Binary)))
+	job, _ := tenant.NewChild("job-1", NoLimit())
+	_, _ = node.NewChild("tenant-b", NewLimit(*New(500*GB, Metric)))
+
+	r, _ := job.Reserve(*New(1228*GiB, Binary))
+	r.Commit()
+	_, _ = node.Reserve(*New(200*GiB, Binary))
+
+	snapshot := node.Snapshot()
+	assertEqual(t, 2, len(snapshot.Children), "Children")
+	assertEqual(t, *New(1228*GiB, Binary), snapshot.Children[0].Children[0].Used, "Used by job")
+	assertEqual(t, "node: 1.199 TiB used, 200 GiB reserved of 4 TiB\n"+
+		"  tenant-a: 1.199 TiB used, 0 B reserved of 2 TiB\n"+
+		"    job-1: 1.199 TiB used, 0 B reserved of unlimited\n"+
+		"  tenant-b: 0 B used, 0 B reserved of 500 GB\n", snapshot.String(), "Snapshot text")
+}
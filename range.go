@@ -0,0 +1,176 @@
+package bytefmt
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Range is an inclusive interval of sizes. Either bound may be omitted, in which
+// case the range is unbounded in that direction.
+type Range struct {
+	Min NullSize
+	Max NullSize
+}
+
+// NewRange returns a range bounded on both ends.
+func NewRange(min, max Size) Range {
+	return Range{
+		Min: NullSize{Size: min, Valid: true},
+		Max: NullSize{Size: max, Valid: true},
+	}
+}
+
+// ParseRange converts a string representation of a range to a Range. Bounds are
+// separated by ".." or "-" and parsed as by Parse. Either bound may be empty.
+//
+//    ParseRange("1MiB..10GiB") = [1 MiB, 10 GiB]
+//    ParseRange("1MiB-10GiB")  = [1 MiB, 10 GiB]
+//    ParseRange("..4GiB")      = [unbounded, 4 GiB]
+//    ParseRange("1 kB..")      = [1 kB, unbounded]
+func ParseRange(s string) (Range, error) {
+	r, err := parseRange(s)
+	if err != nil {
+		return Range{}, fmt.Errorf("can't convert %q to range: %w", s, err)
+	}
+	return r, nil
+}
+
+func parseRange(s string) (Range, error) {
+	min, max, ok := splitRange(s)
+	if !ok {
+		return Range{}, errors.New("missing separator")
+	}
+
+	var r Range
+	if min = strings.TrimSpace(min); min != "" {
+		size, err := parse(min)
+		if err != nil {
+			return Range{}, err
+		}
+		r.Min = NullSize{Size: *size, Valid: true}
+	}
+	if max = strings.TrimSpace(max); max != "" {
+		size, err := parse(max)
+		if err != nil {
+			return Range{}, err
+		}
+		r.Max = NullSize{Size: *size, Valid: true}
+	}
+
+	if r.IsEmpty() {
+		return Range{}, fmt.Errorf("minimum %s exceeds maximum %s",
+			r.Min.Size.String(), r.Max.Size.String())
+	}
+	return r, nil
+}
+
+// splitRange separates a range into its lower and upper bounds.
+func splitRange(s string) (min, max string, ok bool) {
+	if i := strings.Index(s, ".."); i >= 0 {
+		return s[:i], s[i+2:], true
+	}
+
+	// A hyphen may also be a sign, so skip any which begin a bound.
+	for i := 0; i < len(s); i++ {
+		if s[i] != '-' || strings.TrimSpace(s[:i]) == "" {
+			continue
+		}
+		return s[:i], s[i+1:], true
+	}
+	return "", "", false
+}
+
+// IsEmpty returns whether no size falls within the range.
+func (r Range) IsEmpty() bool {
+	return r.Min.Valid && r.Max.Valid && r.Min.Size.Cmp(r.Max.Size) > 0
+}
+
+// Contains returns whether a size falls within the range.
+func (r Range) Contains(s Size) bool {
+	return r.Validate(s) == nil
+}
+
+// Clamp returns the size within the range nearest to s. The result of clamping
+// to an empty range is undefined.
+func (r Range) Clamp(s Size) Size {
+	if r.Min.Valid && s.Cmp(r.Min.Size) < 0 {
+		return r.Min.Size
+	}
+	if r.Max.Valid && s.Cmp(r.Max.Size) > 0 {
+		return r.Max.Size
+	}
+	return s
+}
+
+// Intersect returns the range of sizes within both r and y. It returns false if
+// the ranges don't overlap.
+func (r Range) Intersect(y Range) (Range, bool) {
+	result := r
+	if y.Min.Valid && (!r.Min.Valid || y.Min.Size.Cmp(r.Min.Size) > 0) {
+		result.Min = y.Min
+	}
+	if y.Max.Valid && (!r.Max.Valid || y.Max.Size.Cmp(r.Max.Size) < 0) {
+		result.Max = y.Max
+	}
+	return result, !result.IsEmpty()
+}
+
+// Validate returns an error describing why a size falls outside the range, or
+// nil if the range contains it.
+func (r Range) Validate(s Size) error {
+	if r.Min.Valid && s.Cmp(r.Min.Size) < 0 {
+		return fmt.Errorf("%s is less than the minimum of %s", s.String(), r.Min.Size.String())
+	}
+	if r.Max.Valid && s.Cmp(r.Max.Size) > 0 {
+		return fmt.Errorf("%s is greater than the maximum of %s", s.String(), r.Max.Size.String())
+	}
+	return nil
+}
+
+// String returns the formatted range, omitting unbounded ends.
+func (r Range) String() string {
+	var b strings.Builder
+	if r.Min.Valid {
+		b.WriteString(r.Min.Size.String())
+	}
+	b.WriteString("..")
+	if r.Max.Valid {
+		b.WriteString(r.Max.Size.String())
+	}
+	return b.String()
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (r Range) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (r *Range) UnmarshalText(value []byte) error {
+	parsed, err := ParseRange(string(value))
+	if err != nil {
+		return err
+	}
+	*r = parsed
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (r Range) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(r.String())), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (r *Range) UnmarshalJSON(value []byte) error {
+	if string(value) == "null" {
+		return errors.New("can't decode null as bytefmt.Range")
+	}
+
+	str, err := strconv.Unquote(string(value))
+	if err != nil {
+		return fmt.Errorf("can't decode %q as bytefmt.Range: %w", value, err)
+	}
+	return r.UnmarshalText([]byte(str))
+}
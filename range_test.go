@@ -0,0 +1,131 @@
+package bytefmt
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestParseRange(t *testing.T) {
+	mib := NullSize{Size: *New(MiB, Binary), Valid: true}
+	gib := NullSize{Size: *New(10*GiB, Binary), Valid: true}
+
+	tests := []struct {
+		In        string
+		Expect    Range
+		ExpectErr string
+	}{
+		// Separators
+		{In: "1MiB..10GiB", Expect: Range{Min: mib, Max: gib}},
+		{In: "1MiB-10GiB", Expect: Range{Min: mib, Max: gib}},
+		{In: "1 MiB .. 10 GiB", Expect: Range{Min: mib, Max: gib}},
+		{In: "1 MiB - 10 GiB", Expect: Range{Min: mib, Max: gib}},
+
+		// Half-open ranges
+		{In: "..10GiB", Expect: Range{Max: gib}},
+		{In: "1MiB..", Expect: Range{Min: mib}},
+		{In: "..", Expect: Range{}},
+
+		// Negative bounds
+		{In: "-5--1", Expect: NewRange(*New(-5, Metric), *New(-1, Metric))},
+		{In: "-5..", Expect: Range{Min: NullSize{Size: *New(-5, Metric), Valid: true}}},
+
+		// Invalid values
+		{In: "", ExpectErr: "missing separator"},
+		{In: "1MiB", ExpectErr: "missing separator"},
+		{In: "1MiB..10 GiBs", ExpectErr: `"GiBs" is not a valid byte quantity`},
+		{In: "10GiB..1MiB", ExpectErr: "minimum 10 GiB exceeds maximum 1 MiB"},
+	}
+
+	for _, test := range tests {
+		r, err := ParseRange(test.In)
+
+		if test.ExpectErr != "" {
+			expectErr := fmt.Sprintf("can't convert %q to range: %s", test.In, test.ExpectErr)
+			assertEqualErr(t, expectErr, err, "Error for %q", test.In)
+			continue
+		}
+
+		if !assertNoErr(t, err, "Unxpected error for %q", test.In) {
+			continue
+		}
+		assertEqual(t, test.Expect, r, "Range for %q", test.In)
+	}
+}
+
+func TestRangeValidate(t *testing.T) {
+	r := NewRange(*New(MiB, Binary), *New(10*GiB, Binary))
+	upper := Range{Max: NullSize{Size: *New(4*GiB, Binary), Valid: true}}
+
+	tests := []struct {
+		Range     Range
+		In        *Size
+		Expect    *Size
+		ExpectErr string
+	}{
+		{Range: r, In: New(MiB, Binary), Expect: New(MiB, Binary)},
+		{Range: r, In: New(10*GiB, Binary), Expect: New(10*GiB, Binary)},
+		{Range: r, In: New(MiB-1, Binary), Expect: New(MiB, Binary),
+			ExpectErr: "1048575 B is less than the minimum of 1 MiB"},
+		{Range: r, In: New(11*GiB, Binary), Expect: New(10*GiB, Binary),
+			ExpectErr: "11 GiB is greater than the maximum of 10 GiB"},
+		{Range: upper, In: New(-GiB, Binary), Expect: New(-GiB, Binary)},
+		{Range: upper, In: New(5*GB, Metric), Expect: New(4*GiB, Binary),
+			ExpectErr: "5 GB is greater than the maximum of 4 GiB"},
+		{Range: Range{}, In: New(5*GB, Metric), Expect: New(5*GB, Metric)},
+	}
+
+	for _, test := range tests {
+		err := test.Range.Validate(*test.In)
+		if test.ExpectErr != "" {
+			assertEqualErr(t, test.ExpectErr, err, "Validating %v in %s", test.In, test.Range)
+		} else {
+			assertNoErr(t, err, "Validating %v in %s", test.In, test.Range)
+		}
+		assertEqual(t, test.ExpectErr == "", test.Range.Contains(*test.In),
+			"Whether %s contains %v", test.Range, test.In)
+		assertEqual(t, *test.Expect, test.Range.Clamp(*test.In),
+			"Clamping %v to %s", test.In, test.Range)
+	}
+}
+
+func TestRangeIntersect(t *testing.T) {
+	tests := []struct {
+		A, B    string
+		Expect  string
+		Overlap bool
+	}{
+		{A: "1MiB..10GiB", B: "..4GiB", Expect: "1 MiB..4 GiB", Overlap: true},
+		{A: "..4GiB", B: "1MiB..10GiB", Expect: "1 MiB..4 GiB", Overlap: true},
+		{A: "1MiB..", B: "2MiB..", Expect: "2 MiB..", Overlap: true},
+		{A: "..", B: "..", Expect: "..", Overlap: true},
+		{A: "1GiB..1GiB", B: "1GiB..2GiB", Expect: "1 GiB..1 GiB", Overlap: true},
+		{A: "..1GiB", B: "2GiB..", Expect: "2 GiB..1 GiB", Overlap: false},
+	}
+
+	for _, test := range tests {
+		a, _ := ParseRange(test.A)
+		b, _ := ParseRange(test.B)
+		result, ok := a.Intersect(b)
+		assertEqual(t, test.Expect, result.String(), "Intersecting %s and %s", a, b)
+		assertEqual(t, test.Overlap, ok, "Whether %s and %s overlap", a, b)
+	}
+}
+
+func TestRangeMarshal(t *testing.T) {
+	type config struct {
+		Cache Range `json:"cache"`
+	}
+
+	in := config{Cache: Range{Max: NullSize{Size: *New(4*GiB, Binary), Valid: true}}}
+	b, err := json.Marshal(in)
+	if !assertNoErr(t, err, "Marshalling %v", in) {
+		return
+	}
+	assertEqual(t, `{"cache":"..4 GiB"}`, string(b), "JSON for %v", in)
+
+	var out config
+	if assertNoErr(t, json.Unmarshal(b, &out), "Unmarshalling %s", b) {
+		assertEqual(t, in, out, "Round trip")
+	}
+}
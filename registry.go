@@ -0,0 +1,161 @@
+package bytefmt
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Registry is a set of custom units, such as disk sectors or memory pages, which
+// extends the units understood by Parse. The zero value is an empty registry
+// ready to use.
+//
+//    var units bytefmt.Registry
+//    units.Register(bytefmt.Unit{Name: "sector", Bytes: 512})
+//    units.Register(bytefmt.Unit{Symbol: "8kB", Name: "page", Bytes: 8192})
+//    units.Parse("2048 sectors") = 1,048,576 bytes
+//
+// A registry must not be modified concurrently with other use.
+type Registry struct {
+	units  []Unit
+	byName map[string]Unit // Indexed by lower-case symbol, name, and plural name
+}
+
+// NewRegistry returns a registry containing the given units. It returns an error
+// if any can't be registered.
+func NewRegistry(units ...Unit) (*Registry, error) {
+	r := &Registry{}
+	for _, u := range units {
+		if err := r.Register(u); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Register adds a unit to the registry. The unit may be referred to by its
+// symbol, its name, or its name followed by "s", all case-insensitively.
+//
+// An error is returned if the unit has neither a symbol nor a name, if it
+// doesn't contain a positive number of bytes, if its base is neither zero,
+// Metric, nor Binary, or if any of its identifiers conflict with a built-in unit
+// or one already registered.
+func (r *Registry) Register(u Unit) error {
+	if u.Symbol == "" && u.Name == "" {
+		return errors.New("unit must have a symbol or name")
+	}
+	if u.Bytes <= 0 {
+		return fmt.Errorf("unit %q must contain a positive number of bytes", u.label())
+	}
+	if u.Base != 0 && u.Base != Metric && u.Base != Binary {
+		return fmt.Errorf("unit %q has invalid base %d", u.label(), u.Base)
+	}
+
+	keys := u.keys()
+	for _, key := range keys {
+		if strings.TrimSpace(key) != key {
+			return fmt.Errorf("unit %q can't begin or end with a space", key)
+		}
+		if existing, ok := LookupUnit(key); ok {
+			return fmt.Errorf("unit %q conflicts with built-in unit %s", key, existing.Symbol)
+		}
+		if existing, ok := r.byName[strings.ToLower(key)]; ok {
+			return fmt.Errorf("unit %q conflicts with registered unit %q", key, existing.label())
+		}
+	}
+
+	if r.byName == nil {
+		r.byName = make(map[string]Unit)
+	}
+	for _, key := range keys {
+		r.byName[strings.ToLower(key)] = u
+	}
+	r.units = append(r.units, u)
+	return nil
+}
+
+// Units returns the registered units in the order they were added.
+func (r *Registry) Units() []Unit {
+	return append([]Unit(nil), r.units...)
+}
+
+// Lookup returns the unit identified by s, which may be a registered unit or any
+// unit accepted by LookupUnit.
+func (r *Registry) Lookup(s string) (Unit, bool) {
+	if u, ok := r.byName[strings.ToLower(s)]; ok {
+		return u, true
+	}
+	return LookupUnit(s)
+}
+
+// Parse is like the package-level Parse, but additionally accepts registered
+// units. Fractional bytes are truncated.
+//
+// Since a unit's symbol may begin with a digit, as in "8kB", custom units should
+// be separated from the number by a space.
+func (r *Registry) Parse(s string) (*Size, error) {
+	size, err := parseUnit(s, func(suffix string) (Unit, error) {
+		if u, ok := r.byName[strings.ToLower(suffix)]; ok {
+			return u, nil
+		}
+		return builtinUnit(suffix)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can't convert %q to size: %w", s, err)
+	}
+	return size, nil
+}
+
+// Format returns the size as a number of the named unit, which may be any unit
+// accepted by Lookup. It returns an error if the unit isn't known.
+//
+//    r.Format(*bytefmt.New(bytefmt.MiB, bytefmt.Binary), "sector") = "2048 sectors"
+func (r *Registry) Format(s Size, unit string) (string, error) {
+	u, ok := r.Lookup(unit)
+	if !ok {
+		return "", fmt.Errorf("%q is not a known unit", unit)
+	}
+	return s.FormatIn(u), nil
+}
+
+// FormatIn returns the size as a number of the given unit, using the shortest
+// decimal representation which converts back to the same number. The unit is
+// written as its symbol, or if it has none, as its name. Names are pluralized
+// unless the number is exactly one. Like In, it panics if the unit doesn't have
+// a positive number of bytes.
+//
+//    New(1536*MiB, Binary).FormatIn(Gibibyte) = "1.5 GiB"
+//    New(MiB, Binary).FormatIn(Unit{Name: "sector", Bytes: 512})
+//        = "2048 sectors"
+func (s Size) FormatIn(u Unit) string {
+	n := s.In(u)
+	label := u.Symbol
+	if label == "" {
+		label = u.Name
+		if n != 1 && n != -1 {
+			label += "s"
+		}
+	}
+	return strconv.FormatFloat(n, 'f', -1, 64) + " " + label
+}
+
+// keys returns the identifiers by which a unit may be referred.
+func (u Unit) keys() []string {
+	var keys []string
+	if u.Symbol != "" {
+		keys = append(keys, u.Symbol)
+	}
+	if u.Name != "" && !strings.EqualFold(u.Name, u.Symbol) {
+		keys = append(keys, u.Name, u.Name+"s")
+	}
+	return keys
+}
+
+// label returns a unit's symbol, or its name if it has no symbol.
+func (u Unit) label() string {
+	if u.Symbol != "" {
+		return u.Symbol
+	}
+	return u.Name
+}
@@ -0,0 +1,110 @@
+package bytefmt
+
+import "testing"
+
+var (
+	sector = Unit{Name: "sector", Bytes: 512}
+	page   = Unit{Symbol: "8kB", Name: "page", Bytes: 8 * KiB, Base: Binary}
+)
+
+func TestRegistryParse(t *testing.T) {
+	r, err := NewRegistry(sector, page)
+	if !assertNoErr(t, err, "Creating registry") {
+		return
+	}
+
+	tests := []struct {
+		In          string
+		ExpectBytes int64
+		ExpectBase  Base
+		ExpectErr   string
+	}{
+		{In: "2048 sectors", ExpectBytes: MiB},
+		{In: "1 sector", ExpectBytes: 512},
+		{In: "1.5 SECTORS", ExpectBytes: 768},
+		{In: "0.001 sector", ExpectBytes: 0},
+		{In: "16 8kB", ExpectBytes: 128 * KiB, ExpectBase: Binary},
+		{In: "-2 pages", ExpectBytes: -16 * KiB, ExpectBase: Binary},
+
+		// Built-in units are still accepted.
+		{In: "4 GiB", ExpectBytes: 4 * GiB, ExpectBase: Binary},
+		{In: "10k", ExpectBytes: 10 * KB, ExpectBase: Metric},
+
+		{In: "3 blocks", ExpectErr: `can't convert "3 blocks" to size: "blocks" is not a valid byte quantity`},
+		{In: "168kB", ExpectBytes: 168 * KB, ExpectBase: Metric},
+		{In: "9223372036854775807 sectors", ExpectErr: `can't convert "9223372036854775807 sectors" to size: value exceeds 64 bits`},
+	}
+
+	for _, test := range tests {
+		size, err := r.Parse(test.In)
+		if test.ExpectErr != "" {
+			assertEqualErr(t, test.ExpectErr, err, "Error for %q", test.In)
+			continue
+		}
+		if assertNoErr(t, err, "Parsing %q", test.In) {
+			assertEqual(t, *New(test.ExpectBytes, test.ExpectBase), *size, "Size for %q", test.In)
+		}
+	}
+}
+
+func TestRegistryFormat(t *testing.T) {
+	r, err := NewRegistry(sector, page)
+	if !assertNoErr(t, err, "Creating registry") {
+		return
+	}
+
+	tests := []struct {
+		In     Size
+		Unit   string
+		Expect string
+	}{
+		{In: *New(MiB, Binary), Unit: "sector", Expect: "2048 sectors"},
+		{In: *New(512, Metric), Unit: "sectors", Expect: "1 sector"},
+		{In: *New(-512, Metric), Unit: "Sector", Expect: "-1 sector"},
+		{In: *New(1000, Metric), Unit: "sector", Expect: "1.953125 sectors"},
+		{In: *New(128*KiB, Binary), Unit: "page", Expect: "16 8kB"},
+		{In: *New(1536*MiB, Binary), Unit: "GiB", Expect: "1.5 GiB"},
+		{In: *New(1536*MiB, Binary), Unit: "gigabytes", Expect: "1.610612736 GB"},
+	}
+
+	for _, test := range tests {
+		s, err := r.Format(test.In, test.Unit)
+		if assertNoErr(t, err, "Formatting %v in %s", test.In, test.Unit) {
+			assertEqual(t, test.Expect, s, "Formatted %v in %s", test.In, test.Unit)
+		}
+	}
+
+	_, err = r.Format(Size{}, "block")
+	assertEqualErr(t, `"block" is not a known unit`, err, "Unknown unit")
+}
+
+func TestRegistryConflicts(t *testing.T) {
+	var r Registry
+	assertNoErr(t, r.Register(sector), "Registering sector")
+
+	tests := []struct {
+		In        Unit
+		ExpectErr string
+	}{
+		{In: Unit{Bytes: 1}, ExpectErr: "unit must have a symbol or name"},
+		{In: Unit{Name: "block"}, ExpectErr: `unit "block" must contain a positive number of bytes`},
+		{In: Unit{Name: "block", Bytes: 512, Base: 512}, ExpectErr: `unit "block" has invalid base 512`},
+		{In: Unit{Symbol: "Kb", Name: "kilobit", Bytes: 125}, ExpectErr: `unit "Kb" conflicts with built-in unit kB`},
+		{In: Unit{Name: "Byte", Bytes: 1}, ExpectErr: `unit "Byte" conflicts with built-in unit B`},
+		{In: Unit{Symbol: "s", Name: "Sector", Bytes: 4096}, ExpectErr: `unit "Sector" conflicts with registered unit "sector"`},
+		{In: Unit{Symbol: "SECTORS", Bytes: 4096}, ExpectErr: `unit "SECTORS" conflicts with registered unit "sector"`},
+		{In: Unit{Name: " word", Bytes: 8}, ExpectErr: `unit " word" can't begin or end with a space`},
+	}
+
+	for _, test := range tests {
+		assertEqualErr(t, test.ExpectErr, r.Register(test.In), "Registering %+v", test.In)
+	}
+
+	// Failed registrations have no effect.
+	assertEqual(t, []Unit{sector}, r.Units(), "Registered units")
+	_, ok := r.Lookup("s")
+	assertEqual(t, false, ok, "Found unit from failed registration")
+
+	_, err := NewRegistry(sector, sector)
+	assertEqualErr(t, `unit "sector" conflicts with registered unit "sector"`, err, "Duplicate unit")
+}
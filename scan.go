@@ -0,0 +1,89 @@
+package bytefmt
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// Scanner returns a value which reads a size into p when passed to fmt.Scan,
+// fmt.Sscanf, and related functions. Size can't implement fmt.Scanner itself
+// because its Scan method implements sql.Scanner.
+//
+//    var used, total bytefmt.Size
+//    fmt.Sscanf("3.2 GiB used of 4GiB", "%v used of %v",
+//        bytefmt.Scanner(&used), bytefmt.Scanner(&total))
+//
+// A size is read as a number followed by an optional unit, using the grammar of
+// Parse. The unit may be attached to the number or separated from it by a single
+// space. Since a scanner can only look one rune ahead, this has two caveats:
+//
+//   - A separated word beginning with the first letter of a unit is always read
+//     as one; if it isn't a valid unit, scanning fails.
+//   - A space following a number without a unit is consumed. Where input may
+//     lack units, formats should omit the space after the verb, as in "%vused".
+//
+// The 'v' and 's' verbs are supported.
+func Scanner(p *Size) fmt.Scanner { return sizeScanner{p} }
+
+type sizeScanner struct{ p *Size }
+
+func (s sizeScanner) Scan(state fmt.ScanState, verb rune) error {
+	if verb != 'v' && verb != 's' {
+		return fmt.Errorf("bad verb '%%%c' for bytefmt.Size", verb)
+	}
+
+	var b strings.Builder
+	number, err := state.Token(true, isNumberRune)
+	if err != nil {
+		return err
+	}
+	if len(number) == 0 {
+		return io.ErrUnexpectedEOF
+	}
+	b.Write(number)
+
+	// Read the unit if attached, or peek past a single space to find one.
+	unit, err := state.Token(false, unicode.IsLetter)
+	if err != nil {
+		return err
+	}
+	if len(unit) == 0 {
+		if r, _, err := state.ReadRune(); err == nil {
+			if r != ' ' {
+				_ = state.UnreadRune()
+			} else if r, _, err := state.ReadRune(); err == nil {
+				_ = state.UnreadRune()
+				if isUnitStart(r) {
+					b.WriteByte(' ')
+					if unit, err = state.Token(false, unicode.IsLetter); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	b.Write(unit)
+
+	size, err := Parse(b.String())
+	if err != nil {
+		return err
+	}
+	*s.p = *size
+	return nil
+}
+
+func isNumberRune(r rune) bool {
+	return r == '-' || r == '.' || (r >= '0' && r <= '9')
+}
+
+// isUnitStart returns whether a rune may begin a unit suffix.
+func isUnitStart(r rune) bool {
+	switch unicode.ToLower(r) {
+	case 'b', 'k', 'm', 'g', 't', 'p', 'e':
+		return true
+	default:
+		return false
+	}
+}
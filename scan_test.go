@@ -0,0 +1,60 @@
+package bytefmt
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestScanner(t *testing.T) {
+	tests := []struct {
+		In        string
+		Format    string
+		Expect    []Size
+		ExpectErr string
+	}{
+		// Units may be attached or separated by a space.
+		{In: "3.2 GiB used of 4GiB", Format: "%v used of %v",
+			Expect: []Size{*New(3435973836, Binary), *New(4*GiB, Binary)}},
+		{In: "3.2GiB/4 GiB", Format: "%s/%s",
+			Expect: []Size{*New(3435973836, Binary), *New(4*GiB, Binary)}},
+
+		// Units are optional, but the following space is consumed.
+		{In: "1024 used of 2048", Format: "%vused of %v",
+			Expect: []Size{*New(1024, Metric), *New(2048, Metric)}},
+		{In: "1024 used of 2048", Format: "%v used of %v",
+			ExpectErr: "expected space in input to match format"},
+		{In: "-1, 2k", Format: "%v, %v",
+			Expect: []Size{*New(-1, Metric), *New(2*KB, Metric)}},
+
+		// Words resembling units are read as units.
+		{In: "512 bytes", Format: "%v bytes",
+			ExpectErr: `can't convert "512 bytes" to size: "bytes" is not a valid byte quantity`},
+
+		// Invalid input
+		{In: "lots", Format: "%v", ExpectErr: "unexpected EOF"},
+		{In: "1 kB", Format: "%d", ExpectErr: "bad verb '%d' for bytefmt.Size"},
+	}
+
+	for _, test := range tests {
+		sizes := make([]Size, 2)
+		_, err := fmt.Sscanf(test.In, test.Format, Scanner(&sizes[0]), Scanner(&sizes[1]))
+
+		if test.ExpectErr != "" {
+			assertEqualErr(t, test.ExpectErr, err, "Scanning %q", test.In)
+			continue
+		}
+		if assertNoErr(t, err, "Scanning %q", test.In) {
+			assertEqual(t, test.Expect, sizes, "Sizes in %q", test.In)
+		}
+	}
+}
+
+func TestScannerSscan(t *testing.T) {
+	var a, b, c Size
+	n, err := fmt.Sscan("1 KiB 2MB 3", Scanner(&a), Scanner(&b), Scanner(&c))
+	if assertNoErr(t, err, "Scanning") {
+		assertEqual(t, 3, n, "Count scanned")
+		assertEqual(t, []Size{*New(KiB, Binary), *New(2*MB, Metric), *New(3, Metric)},
+			[]Size{a, b, c}, "Sizes scanned")
+	}
+}
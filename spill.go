@@ -0,0 +1,185 @@
+package bytefmt
+
+import (
+	"errors"
+	"io"
+	"os"
+)
+
+var errSpillClosed = errors.New("spill buffer is closed")
+
+// SpillBuffer buffers data in memory up to a threshold, beyond which it moves
+// the data to a temporary file. It's useful for holding content of unknown size,
+// such as an upload, which is usually small but occasionally large.
+//
+//    buf := bytefmt.NewSpillBuffer(*bytefmt.New(32*bytefmt.MiB, bytefmt.Binary), "")
+//    defer buf.Close()
+//    io.Copy(buf, req.Body)
+//    buf.Seek(0, io.SeekStart)
+//    io.Copy(dst, buf)
+//
+// Like a file, a SpillBuffer has a single offset shared by Read, Write, and Seek.
+// It isn't safe for concurrent use.
+type SpillBuffer struct {
+	threshold Size
+	dir       string
+
+	mem    []byte
+	file   *os.File
+	off    int64
+	size   int64
+	closed bool
+}
+
+// NewSpillBuffer returns an empty buffer which holds up to threshold bytes in
+// memory before spilling to a temporary file in dir. If dir is empty, the
+// default directory for temporary files is used.
+func NewSpillBuffer(threshold Size, dir string) *SpillBuffer {
+	return &SpillBuffer{threshold: threshold, dir: dir}
+}
+
+// Write implements the io.Writer interface, writing at the current offset.
+func (b *SpillBuffer) Write(p []byte) (int, error) {
+	if b.closed {
+		return 0, errSpillClosed
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	end := b.off + int64(len(p))
+	if b.file == nil && end > b.threshold.bytes {
+		if err := b.spill(); err != nil {
+			return 0, err
+		}
+	}
+
+	if b.file != nil {
+		n, err := b.file.WriteAt(p, b.off)
+		b.advance(n)
+		return n, err
+	}
+
+	if end > int64(len(b.mem)) {
+		if end > int64(cap(b.mem)) {
+			grown := make([]byte, len(b.mem), growCap(cap(b.mem), end, b.threshold.bytes))
+			copy(grown, b.mem)
+			b.mem = grown
+		}
+		b.mem = b.mem[:end]
+	}
+	n := copy(b.mem[b.off:], p)
+	b.advance(n)
+	return n, nil
+}
+
+// growCap returns the capacity to which a buffer should grow to hold n bytes,
+// doubling to amortize copies but never exceeding max.
+func growCap(c int, n, max int64) int {
+	grown := int64(2 * c)
+	if grown < n {
+		grown = n
+	}
+	if grown > max {
+		grown = max
+	}
+	return int(grown)
+}
+
+// Read implements the io.Reader interface, reading from the current offset.
+func (b *SpillBuffer) Read(p []byte) (int, error) {
+	if b.closed {
+		return 0, errSpillClosed
+	}
+	if b.off >= b.size {
+		return 0, io.EOF
+	}
+	if remaining := b.size - b.off; int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+
+	if b.file != nil {
+		n, err := b.file.ReadAt(p, b.off)
+		b.off += int64(n)
+		if err == io.EOF && n == len(p) {
+			err = nil
+		}
+		return n, err
+	}
+
+	n := copy(p, b.mem[b.off:])
+	b.off += int64(n)
+	return n, nil
+}
+
+// Seek implements the io.Seeker interface. Seeking beyond the end is allowed;
+// a subsequent write fills the gap with zeros.
+func (b *SpillBuffer) Seek(offset int64, whence int) (int64, error) {
+	if b.closed {
+		return 0, errSpillClosed
+	}
+
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += b.off
+	case io.SeekEnd:
+		offset += b.size
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+	b.off = offset
+	return offset, nil
+}
+
+// Size returns the length of the buffered data, in the threshold's base.
+func (b *SpillBuffer) Size() Size { return Size{bytes: b.size, Base: b.threshold.Base} }
+
+// Spilled returns whether the data has been moved to a temporary file.
+func (b *SpillBuffer) Spilled() bool { return b.file != nil }
+
+// Close releases the buffer's memory and removes its temporary file, if any.
+// Further calls have no effect.
+func (b *SpillBuffer) Close() error {
+	if b.closed {
+		return nil
+	}
+	b.closed = true
+	b.mem = nil
+	if b.file == nil {
+		return nil
+	}
+
+	err := b.file.Close()
+	if removeErr := os.Remove(b.file.Name()); err == nil {
+		err = removeErr
+	}
+	return err
+}
+
+// spill moves the buffered data to a temporary file.
+func (b *SpillBuffer) spill() error {
+	f, err := os.CreateTemp(b.dir, "bytefmt-spill-*")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b.mem); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	b.file = f
+	b.mem = nil
+	return nil
+}
+
+// advance moves the offset past n bytes just written.
+func (b *SpillBuffer) advance(n int) {
+	b.off += int64(n)
+	if b.off > b.size {
+		b.size = b.off
+	}
+}
@@ -0,0 +1,110 @@
+package bytefmt
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSpillBuffer(t *testing.T) {
+	dir := t.TempDir()
+	b := NewSpillBuffer(*New(10, Binary), dir)
+
+	_, err := io.WriteString(b, "hello")
+	assertNoErr(t, err, "Writing in memory")
+	assertEqual(t, false, b.Spilled(), "Spilled within threshold")
+
+	_, err = io.WriteString(b, "world")
+	assertNoErr(t, err, "Writing to the threshold")
+	assertEqual(t, false, b.Spilled(), "Spilled at threshold")
+	assertEqual(t, 0, countFiles(t, dir), "Files at threshold")
+
+	_, err = io.WriteString(b, "!")
+	assertNoErr(t, err, "Writing beyond the threshold")
+	assertEqual(t, true, b.Spilled(), "Spilled beyond threshold")
+	assertEqual(t, 1, countFiles(t, dir), "Files after spilling")
+	assertEqual(t, *New(11, Binary), b.Size(), "Size after spilling")
+
+	_, err = b.Seek(0, io.SeekStart)
+	assertNoErr(t, err, "Seeking")
+	content, err := io.ReadAll(b)
+	assertNoErr(t, err, "Reading")
+	assertEqual(t, "helloworld!", string(content), "Content")
+
+	assertNoErr(t, b.Close(), "Closing")
+	assertNoErr(t, b.Close(), "Closing twice")
+	assertEqual(t, 0, countFiles(t, dir), "Files after closing")
+	_, err = b.Write([]byte("x"))
+	assertEqualErr(t, "spill buffer is closed", err, "Writing after close")
+}
+
+func TestSpillBufferSeek(t *testing.T) {
+	for _, threshold := range []int64{0, 8, 1024} {
+		b := NewSpillBuffer(*New(threshold, Metric), t.TempDir())
+
+		// Overwrite within the data, then write beyond its end.
+		_, _ = io.WriteString(b, "abcdef")
+		pos, err := b.Seek(-4, io.SeekCurrent)
+		assertNoErr(t, err, "Seeking back (threshold %d)", threshold)
+		assertEqual(t, int64(2), pos, "Position after seeking back (threshold %d)", threshold)
+		_, _ = io.WriteString(b, "XY")
+		_, _ = b.Seek(2, io.SeekEnd)
+		_, _ = io.WriteString(b, "gh")
+
+		assertEqual(t, *New(10, Metric), b.Size(), "Size (threshold %d)", threshold)
+		assertEqual(t, threshold < 10, b.Spilled(), "Spilled (threshold %d)", threshold)
+
+		_, _ = b.Seek(0, io.SeekStart)
+		content, err := io.ReadAll(b)
+		assertNoErr(t, err, "Reading (threshold %d)", threshold)
+		assertEqual(t, "abXYef\x00\x00gh", string(content), "Content (threshold %d)", threshold)
+
+		// Reading a part leaves the offset after it.
+		_, _ = b.Seek(3, io.SeekStart)
+		part := make([]byte, 4)
+		_, err = io.ReadFull(b, part)
+		assertNoErr(t, err, "Reading part (threshold %d)", threshold)
+		assertEqual(t, "Yef\x00", string(part), "Part (threshold %d)", threshold)
+		pos, _ = b.Seek(0, io.SeekCurrent)
+		assertEqual(t, int64(7), pos, "Position after reading part (threshold %d)", threshold)
+
+		_, err = b.Seek(-11, io.SeekEnd)
+		assertEqualErr(t, "negative position", err, "Seeking before start (threshold %d)", threshold)
+		assertNoErr(t, b.Close(), "Closing (threshold %d)", threshold)
+	}
+}
+
+func TestSpillBufferLarge(t *testing.T) {
+	dir := t.TempDir()
+	b := NewSpillBuffer(*New(32*KiB, Binary), dir)
+	defer b.Close()
+
+	in := strings.Repeat("0123456789abcdef", 4*1024)
+	n, err := io.Copy(b, strings.NewReader(in))
+	assertNoErr(t, err, "Copying")
+	assertEqual(t, int64(len(in)), n, "Bytes copied")
+	assertEqual(t, *New(64*KiB, Binary), b.Size(), "Size")
+	assertEqual(t, true, b.Spilled(), "Spilled")
+
+	_, _ = b.Seek(0, io.SeekStart)
+	var out strings.Builder
+	_, err = io.Copy(&out, b)
+	assertNoErr(t, err, "Copying back")
+	assertEqual(t, in, out.String(), "Content copied back")
+}
+
+func countFiles(t *testing.T, dir string) int {
+	t.Helper()
+	matches, err := filepath.Glob(filepath.Join(dir, "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range matches {
+		if _, err := os.Stat(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return len(matches)
+}
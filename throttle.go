@@ -0,0 +1,262 @@
+package bytefmt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ParseRate parses a transfer rate in bytes per second, such as "50 MiB/s". The
+// "/s" suffix is optional; the rest is parsed as by Parse.
+//
+//    ParseRate("50 MiB/s") = 50 MiB
+//    ParseRate("1.5gb")    = 1500 MB
+func ParseRate(s string) (Size, error) {
+	text := s
+	if n := len(text) - len("/s"); n >= 0 && strings.EqualFold(text[n:], "/s") {
+		text = text[:n]
+	}
+	size, err := parse(text)
+	if err != nil {
+		return Size{}, fmt.Errorf("can't convert %q to rate: %w", s, err)
+	}
+	if size.bytes < 0 {
+		return Size{}, fmt.Errorf("can't convert %q to rate: must not be negative", s)
+	}
+	return *size, nil
+}
+
+// Clock provides the current time. It may be replaced to control time in tests.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// TimerClock is a Clock which also provides timers, as needed by Limiter.
+type TimerClock interface {
+	Clock
+
+	// After returns a channel which receives the current time once d has passed,
+	// as in time.After.
+	After(d time.Duration) <-chan time.Time
+}
+
+// Limiter is a token bucket which limits throughput to a rate in bytes per
+// second. Tokens accumulate at the rate up to the burst size, and each byte
+// transferred consumes one. A limiter may be shared to limit the combined
+// throughput of several readers or writers.
+//
+// Limiter is safe for concurrent use.
+type Limiter struct {
+	// Clock provides the time, defaulting to the system clock. It must be set
+	// before the limiter is used.
+	Clock TimerClock
+
+	mu      sync.Mutex
+	rate    Size
+	burst   Size
+	tokens  float64
+	last    time.Time
+	started bool
+}
+
+// NewLimiter returns a limiter allowing rate bytes per second with bursts of up
+// to burst bytes. A rate of zero or less is unlimited. If burst is zero or less,
+// it defaults to one second's worth of the rate.
+//
+// The bucket starts full, so an initial burst is allowed immediately.
+func NewLimiter(rate, burst Size) *Limiter {
+	l := &Limiter{}
+	l.set(rate, burst)
+	l.tokens = float64(l.burst.bytes)
+	return l
+}
+
+// Rate returns the limiter's rate in bytes per second.
+func (l *Limiter) Rate() Size {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.rate
+}
+
+// Burst returns the limiter's burst size.
+func (l *Limiter) Burst() Size {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.burst
+}
+
+// SetRate changes the limiter's rate and burst size, as in NewLimiter. Tokens
+// accumulated at the previous rate are kept, up to the new burst size. Waits
+// already in progress aren't affected.
+func (l *Limiter) SetRate(rate, burst Size) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.advance(l.clock().Now())
+	l.set(rate, burst)
+	if max := float64(l.burst.bytes); l.tokens > max {
+		l.tokens = max
+	}
+}
+
+// WaitN blocks until n bytes may be transferred, or until ctx is done. It returns
+// an error if n exceeds the burst size, or the context's error if it's done
+// first, in which case no tokens are consumed.
+func (l *Limiter) WaitN(ctx context.Context, n int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	if l.rate.bytes <= 0 {
+		l.mu.Unlock()
+		return nil
+	}
+	if n > l.burst.bytes {
+		burst := l.burst
+		l.mu.Unlock()
+		return fmt.Errorf("can't wait for %s, which exceeds the burst size of %s",
+			Size{bytes: n, Base: burst.Base}.String(), burst.String())
+	}
+
+	clock := l.clock()
+	l.advance(clock.Now())
+	l.tokens -= float64(n)
+	var wait time.Duration
+	if l.tokens < 0 {
+		wait = time.Duration(-l.tokens / float64(l.rate.bytes) * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+	select {
+	case <-clock.After(wait):
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens += float64(n)
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+func (l *Limiter) set(rate, burst Size) {
+	if burst.bytes <= 0 {
+		burst = rate
+	}
+	l.rate, l.burst = rate, burst
+}
+
+// advance adds tokens accumulated since the last update.
+func (l *Limiter) advance(now time.Time) {
+	if !l.started {
+		l.started = true
+		l.last = now
+		return
+	}
+	if elapsed := now.Sub(l.last); elapsed > 0 && l.rate.bytes > 0 {
+		l.tokens += elapsed.Seconds() * float64(l.rate.bytes)
+		if max := float64(l.burst.bytes); l.tokens > max {
+			l.tokens = max
+		}
+	}
+	l.last = now
+}
+
+func (l *Limiter) clock() TimerClock {
+	if l.Clock == nil {
+		return systemClock{}
+	}
+	return l.Clock
+}
+
+// maxChunk returns the largest number of bytes which may be waited for at once.
+func (l *Limiter) maxChunk(n int) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.rate.bytes > 0 && int64(n) > l.burst.bytes {
+		return int(l.burst.bytes)
+	}
+	return n
+}
+
+var errZeroBurst = errors.New("limiter's burst size is less than one byte")
+
+// ThrottleReader returns a reader which limits reads from r to the rate allowed
+// by l. Reads fail with the context's error once ctx is done.
+//
+//    limiter := bytefmt.NewLimiter(rate, bytefmt.Size{})
+//    io.Copy(dst, bytefmt.ThrottleReader(ctx, src, limiter))
+func ThrottleReader(ctx context.Context, r io.Reader, l *Limiter) io.Reader {
+	return &throttledReader{ctx, r, l}
+}
+
+type throttledReader struct {
+	ctx context.Context
+	r   io.Reader
+	l   *Limiter
+}
+
+func (t *throttledReader) Read(p []byte) (int, error) {
+	if err := t.ctx.Err(); err != nil {
+		return 0, err
+	}
+	if len(p) == 0 {
+		return t.r.Read(p)
+	}
+
+	max := t.l.maxChunk(len(p))
+	if max <= 0 {
+		return 0, errZeroBurst
+	}
+	n, err := t.r.Read(p[:max])
+	if waitErr := t.l.WaitN(t.ctx, int64(n)); waitErr != nil {
+		return n, waitErr
+	}
+	return n, err
+}
+
+// ThrottleWriter returns a writer which limits writes to w to the rate allowed
+// by l. Writes larger than the limiter's burst size are split. Writes fail with
+// the context's error once ctx is done.
+func ThrottleWriter(ctx context.Context, w io.Writer, l *Limiter) io.Writer {
+	return &throttledWriter{ctx, w, l}
+}
+
+type throttledWriter struct {
+	ctx context.Context
+	w   io.Writer
+	l   *Limiter
+}
+
+func (t *throttledWriter) Write(p []byte) (int, error) {
+	var written int
+	for len(p) > 0 {
+		max := t.l.maxChunk(len(p))
+		if max <= 0 {
+			return written, errZeroBurst
+		}
+		if err := t.l.WaitN(t.ctx, int64(max)); err != nil {
+			return written, err
+		}
+
+		n, err := t.w.Write(p[:max])
+		written += n
+		if err != nil {
+			return written, err
+		}
+		p = p[max:]
+	}
+	return written, nil
+}
@@ -0,0 +1,161 @@
+package bytefmt
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		In        string
+		Expect    Size
+		ExpectErr string
+	}{
+		{In: "50 MiB/s", Expect: *New(50*MiB, Binary)},
+		{In: "50MiB/S", Expect: *New(50*MiB, Binary)},
+		{In: "1.5gb", Expect: *New(1500*MB, Metric)},
+		{In: "0/s", Expect: *New(0, Metric)},
+		{In: "/s", ExpectErr: `can't convert "/s" to rate: empty string`},
+		{In: "50 MiB/m", ExpectErr: `can't convert "50 MiB/m" to rate: "MiB/m" is not a valid byte quantity`},
+		{In: "-1 MB/s", ExpectErr: `can't convert "-1 MB/s" to rate: must not be negative`},
+	}
+
+	for _, test := range tests {
+		rate, err := ParseRate(test.In)
+		if test.ExpectErr != "" {
+			assertEqualErr(t, test.ExpectErr, err, "Error for %q", test.In)
+			continue
+		}
+		if assertNoErr(t, err, "Parsing %q", test.In) {
+			assertEqual(t, test.Expect, rate, "Rate for %q", test.In)
+		}
+	}
+}
+
+// fakeClock is a manually advanced clock.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// After implements TimerClock. Timers fire immediately, advancing the clock by
+// their duration.
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.Advance(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.Now()
+	return ch
+}
+
+func TestLimiter(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	l := NewLimiter(*New(KB, Metric), *New(2*KB, Metric))
+	l.Clock = clock
+	ctx := context.Background()
+
+	// The initial burst is immediate.
+	assertNoErr(t, l.WaitN(ctx, 2000), "Waiting for the burst")
+	assertEqual(t, time.Unix(0, 0), clock.Now(), "Time after the burst")
+
+	// Further bytes wait for tokens to accumulate.
+	assertNoErr(t, l.WaitN(ctx, 500), "Waiting for tokens")
+	assertEqual(t, time.Unix(0, 0).Add(500*time.Millisecond), clock.Now(), "Time after waiting")
+
+	// Tokens accumulate up to the burst size.
+	clock.Advance(time.Hour)
+	start := clock.Now()
+	assertNoErr(t, l.WaitN(ctx, 2000), "Waiting after idling")
+	assertNoErr(t, l.WaitN(ctx, 1000), "Waiting beyond the burst after idling")
+	assertEqual(t, time.Second, clock.Now().Sub(start), "Time waited after idling")
+
+	err := l.WaitN(ctx, 2001)
+	assertEqualErr(t, "can't wait for 2001 B, which exceeds the burst size of 2 kB", err, "Wait exceeding burst")
+
+	// Changing the rate takes effect for later waits.
+	l.SetRate(*New(4*KB, Metric), Size{})
+	assertEqual(t, *New(4*KB, Metric), l.Rate(), "Rate after change")
+	assertEqual(t, *New(4*KB, Metric), l.Burst(), "Default burst after change")
+	start = clock.Now()
+	assertNoErr(t, l.WaitN(ctx, 4000), "Waiting at the new rate")
+	assertEqual(t, time.Second, clock.Now().Sub(start), "Time waited at the new rate")
+
+	// A zero rate is unlimited.
+	l.SetRate(Size{}, Size{})
+	start = clock.Now()
+	assertNoErr(t, l.WaitN(ctx, 1<<40), "Waiting without a limit")
+	assertEqual(t, time.Duration(0), clock.Now().Sub(start), "Time waited without a limit")
+}
+
+// stoppedClock is a clock whose timers never fire.
+type stoppedClock struct{ fakeClock }
+
+func (*stoppedClock) After(time.Duration) <-chan time.Time { return nil }
+
+func TestLimiterCancel(t *testing.T) {
+	l := NewLimiter(*New(KB, Metric), Size{})
+	l.Clock = &stoppedClock{fakeClock{now: time.Unix(0, 0)}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	assertNoErr(t, l.WaitN(ctx, 1000), "Waiting for the burst")
+
+	done := make(chan error)
+	go func() { done <- l.WaitN(ctx, 1000) }()
+	cancel()
+	assertEqualErr(t, "context canceled", <-done, "Cancelled wait")
+	assertEqualErr(t, "context canceled", l.WaitN(ctx, 1), "Wait after cancellation")
+
+	// Tokens consumed by the cancelled wait are returned.
+	assertNoErr(t, l.WaitN(context.Background(), 0), "Waiting for nothing")
+	l.mu.Lock()
+	assertEqual(t, 0.0, l.tokens, "Tokens after cancellation")
+	l.mu.Unlock()
+}
+
+func TestThrottle(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	l := NewLimiter(*New(KiB, Binary), *New(KiB, Binary))
+	l.Clock = clock
+	ctx := context.Background()
+
+	// The reader and writer share the limiter, so the copy is limited twice.
+	in := strings.Repeat("x", 4*int(KiB))
+	var out bytes.Buffer
+	n, err := io.Copy(ThrottleWriter(ctx, &out, l), ThrottleReader(ctx, strings.NewReader(in), l))
+	assertNoErr(t, err, "Copying")
+	assertEqual(t, int64(len(in)), n, "Bytes copied")
+	assertEqual(t, in, out.String(), "Content copied")
+	assertEqual(t, 7*time.Second, clock.Now().Sub(time.Unix(0, 0)), "Time copying")
+
+	// Large writes are split into bursts.
+	start := clock.Now()
+	w := ThrottleWriter(ctx, ioutil.Discard, l)
+	written, err := w.Write(make([]byte, 3*KiB))
+	assertNoErr(t, err, "Writing")
+	assertEqual(t, int(3*KiB), written, "Bytes written")
+	assertEqual(t, 3*time.Second, clock.Now().Sub(start), "Time writing")
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = ThrottleReader(cancelled, strings.NewReader(in), l).Read(make([]byte, 1))
+	assertEqualErr(t, "context canceled", err, "Reading after cancellation")
+	_, err = ThrottleWriter(cancelled, ioutil.Discard, l).Write(make([]byte, 1))
+	assertEqualErr(t, "context canceled", err, "Writing after cancellation")
+}
@@ -0,0 +1,25 @@
+package bytefmt
+
+// TOML decoders such as github.com/BurntSushi/toml encode sizes through
+// MarshalText, but only call UnmarshalText for strings. UnmarshalTOML covers
+// integers and floats as well.
+
+// UnmarshalTOML implements the toml.Unmarshaler interface. It accepts strings
+// and numbers, which are interpreted as a count of bytes.
+func (s *Size) UnmarshalTOML(value interface{}) error {
+	return s.decode(value, "TOML")
+}
+
+// UnmarshalTOML implements the toml.Unmarshaler interface. It accepts strings
+// and numbers, which are interpreted as a count of bytes. An empty string sets
+// the size to null.
+func (s *NullSize) UnmarshalTOML(value interface{}) error {
+	if str, ok := value.(string); ok {
+		return s.Set(str)
+	}
+	if err := s.Size.decode(value, "TOML"); err != nil {
+		return err
+	}
+	s.Valid = true
+	return nil
+}
@@ -0,0 +1,43 @@
+package bytefmt
+
+import "testing"
+
+func TestUnmarshalTOML(t *testing.T) {
+	tests := []struct {
+		In        interface{}
+		Expect    Size
+		ExpectErr string
+	}{
+		// Values as produced by BurntSushi/toml.
+		{In: "512MiB", Expect: *New(512*MiB, Binary)},
+		{In: int64(1048576), Expect: *New(MiB, Metric)},
+		{In: float64(1e6), Expect: *New(MB, Metric)},
+
+		// Invalid values
+		{In: float64(0.5), ExpectErr: "can't decode TOML value 0.5 as bytefmt.Size: 0.5 is not a whole number of bytes"},
+		{In: []interface{}{}, ExpectErr: "can't decode TOML value '[]' of type '[]interface {}' as bytefmt.Size"},
+	}
+
+	for _, test := range tests {
+		var size Size
+		err := size.UnmarshalTOML(test.In)
+
+		if test.ExpectErr != "" {
+			assertEqualErr(t, test.ExpectErr, err, "Error for %v", test.In)
+			continue
+		}
+		if assertNoErr(t, err, "Unexpected error for %v", test.In) {
+			assertEqual(t, test.Expect, size, "Size for %v", test.In)
+		}
+
+		var null NullSize
+		if assertNoErr(t, null.UnmarshalTOML(test.In), "Unexpected error for %v", test.In) {
+			assertEqual(t, NullSize{Size: test.Expect, Valid: true}, null, "NullSize for %v", test.In)
+		}
+	}
+
+	null := NullSize{Size: *New(1, Metric), Valid: true}
+	if assertNoErr(t, null.UnmarshalTOML(""), "Unmarshalling empty string") {
+		assertEqual(t, NullSize{}, null, "NullSize for empty string")
+	}
+}
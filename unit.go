@@ -2,6 +2,7 @@ package bytefmt
 
 import (
 	"fmt"
+	"math/big"
 	"strings"
 )
 
@@ -28,18 +29,9 @@ const (
 	GB = 1000 * MB
 	TB = 1000 * GB
 	PB = 1000 * TB
+	EB = 1000 * PB
 )
 
-var metricSuffixes = [...]string{
-	"B",
-	"kB", // Intentionally lower-case per SI standard.
-	"MB",
-	"GB",
-	"TB",
-	"PB",
-	"EB",
-}
-
 // Binary suffixes scale quantities by powers of 1024.
 const (
 	KiB = 1024 * Byte
@@ -47,47 +39,180 @@ const (
 	GiB = 1024 * MiB
 	TiB = 1024 * GiB
 	PiB = 1024 * TiB
+	EiB = 1024 * PiB
+)
+
+// Unit is a named quantity of bytes by which sizes are scaled.
+type Unit struct {
+	// Symbol is the abbreviation by which sizes are formatted, such as "kB".
+	Symbol string
+
+	// Name is the unit's long name, such as "kilobyte".
+	Name string
+
+	// Base and Exp determine the unit's scale, such that it contains Base^Exp bytes.
+	Base Base
+	Exp  int
+
+	// Bytes is the number of bytes in the unit.
+	Bytes int64
+}
+
+// Units of bytes. Each corresponds to a constant, such as KB for Kilobyte.
+// They're variables only because Go has no struct constants, and must not be
+// modified.
+var (
+	ByteUnit = Unit{"B", "byte", Metric, 0, Byte}
+
+	Kilobyte = Unit{"kB", "kilobyte", Metric, 1, KB} // Intentionally lower-case per SI standard.
+	Megabyte = Unit{"MB", "megabyte", Metric, 2, MB}
+	Gigabyte = Unit{"GB", "gigabyte", Metric, 3, GB}
+	Terabyte = Unit{"TB", "terabyte", Metric, 4, TB}
+	Petabyte = Unit{"PB", "petabyte", Metric, 5, PB}
+	Exabyte  = Unit{"EB", "exabyte", Metric, 6, EB}
+
+	Kibibyte = Unit{"KiB", "kibibyte", Binary, 1, KiB}
+	Mebibyte = Unit{"MiB", "mebibyte", Binary, 2, MiB}
+	Gibibyte = Unit{"GiB", "gibibyte", Binary, 3, GiB}
+	Tebibyte = Unit{"TiB", "tebibyte", Binary, 4, TiB}
+	Pebibyte = Unit{"PiB", "pebibyte", Binary, 5, PiB}
+	Exbibyte = Unit{"EiB", "exbibyte", Binary, 6, EiB}
 )
 
-var binarySuffixes = [...]string{
-	"B",
-	"KiB",
-	"MiB",
-	"GiB",
-	"TiB",
-	"PiB",
-	"EiB",
+// Units indexed by exponent in each base.
+var (
+	metricUnits = [...]Unit{ByteUnit, Kilobyte, Megabyte, Gigabyte, Terabyte, Petabyte, Exabyte}
+	binaryUnits = [...]Unit{ByteUnit, Kibibyte, Mebibyte, Gibibyte, Tebibyte, Pebibyte, Exbibyte}
+)
+
+// Units returns all units: bytes, followed by metric then binary units in
+// increasing order of size.
+func Units() []Unit {
+	units := make([]Unit, 0, len(metricUnits)+len(binaryUnits)-1)
+	units = append(units, metricUnits[:]...)
+	return append(units, binaryUnits[1:]...)
+}
+
+// LookupUnit returns the unit identified by s, which may be any suffix accepted
+// by Parse or a long name such as "gibibyte" or "gibibytes". Matching is
+// case-insensitive.
+func LookupUnit(s string) (Unit, bool) {
+	if s != "" {
+		if exp, base, ok := lookupSuffix([]byte(s)); ok {
+			if base == Binary {
+				return binaryUnits[exp], true
+			}
+			return metricUnits[exp], true
+		}
+	}
+
+	for _, u := range Units() {
+		if strings.EqualFold(s, u.Name) || strings.EqualFold(s, u.Name+"s") {
+			return u, true
+		}
+	}
+	return Unit{}, false
+}
+
+// String returns the unit's symbol.
+func (u Unit) String() string { return u.Symbol }
+
+// In returns the size as a number of units, rounded to the nearest float64. It
+// panics if the unit doesn't have a positive number of bytes, as for Unit{}.
+//
+//    New(1536*MiB, Binary).In(Gibibyte) = 1.5
+func (s Size) In(u Unit) float64 {
+	u.mustBePositive()
+	f, _ := new(big.Rat).SetFrac64(s.bytes, u.Bytes).Float64()
+	return f
+}
+
+// Split divides the size into a whole number of units and the remainder, such
+// that whole+remainder equals the size exactly. As with integer division, the
+// quotient is truncated toward zero and the remainder takes the size's sign. Both
+// results use the unit's base. Like In, it panics if the unit doesn't have a
+// positive number of bytes.
+//
+//    New(3584*MiB, Binary).Split(Gibibyte) = 3 GiB, 512 MiB
+func (s Size) Split(u Unit) (whole, remainder Size) {
+	u.mustBePositive()
+	whole = Size{bytes: s.bytes / u.Bytes * u.Bytes, Base: u.Base}
+	remainder = Size{bytes: s.bytes % u.Bytes, Base: u.Base}
+	return whole, remainder
+}
+
+func (u Unit) mustBePositive() {
+	if u.Bytes <= 0 {
+		panic(fmt.Sprintf("bytefmt: unit %q has %d bytes; must be positive", u.label(), u.Bytes))
+	}
 }
 
 func parseSuffix(s string) (int, Base, error) {
-	switch strings.ToLower(s) {
-	case "b", "":
-		return 0, Metric, nil
-	case "kb", "k":
-		return 1, Metric, nil
-	case "mb", "m":
-		return 2, Metric, nil
-	case "gb", "g":
-		return 3, Metric, nil
-	case "tb", "t":
-		return 4, Metric, nil
-	case "pb", "p":
-		return 5, Metric, nil
-	case "eb", "e":
-		return 6, Metric, nil
-	case "kib":
-		return 1, Binary, nil
-	case "mib":
-		return 2, Binary, nil
-	case "gib":
-		return 3, Binary, nil
-	case "tib":
-		return 4, Binary, nil
-	case "pib":
-		return 5, Binary, nil
-	case "eib":
-		return 6, Binary, nil
-	default:
+	exp, base, ok := lookupSuffix([]byte(s))
+	if !ok {
 		return 0, Metric, fmt.Errorf("%q is not a valid byte quantity", s)
 	}
+	return exp, base, nil
+}
+
+// builtinUnit returns the unit for a suffix accepted by parseSuffix.
+func builtinUnit(suffix string) (Unit, error) {
+	exp, base, err := parseSuffix(suffix)
+	if err != nil {
+		return Unit{}, err
+	}
+	if base == Binary {
+		return binaryUnits[exp], nil
+	}
+	return metricUnits[exp], nil
+}
+
+// lookupSuffix returns the scale of a case-insensitive unit suffix. Metric
+// suffixes may omit the trailing "B"; binary suffixes may not.
+func lookupSuffix(b []byte) (exp int, base Base, ok bool) {
+	switch len(b) {
+	case 0:
+		return 0, Metric, true
+	case 1:
+		if lower(b[0]) == 'b' {
+			return 0, Metric, true
+		}
+		exp = prefixExp(b[0])
+		return exp, Metric, exp != 0
+	case 2:
+		exp = prefixExp(b[0])
+		return exp, Metric, exp != 0 && lower(b[1]) == 'b'
+	case 3:
+		exp = prefixExp(b[0])
+		return exp, Binary, exp != 0 && lower(b[1]) == 'i' && lower(b[2]) == 'b'
+	default:
+		return 0, Metric, false
+	}
+}
+
+// prefixExp returns the exponent of a unit prefix, or zero if it isn't one.
+func prefixExp(c byte) int {
+	switch lower(c) {
+	case 'k':
+		return 1
+	case 'm':
+		return 2
+	case 'g':
+		return 3
+	case 't':
+		return 4
+	case 'p':
+		return 5
+	case 'e':
+		return 6
+	default:
+		return 0
+	}
+}
+
+func lower(c byte) byte {
+	if c >= 'A' && c <= 'Z' {
+		return c + 'a' - 'A'
+	}
+	return c
 }
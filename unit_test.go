@@ -0,0 +1,103 @@
+package bytefmt
+
+import (
+	"math"
+	"testing"
+)
+
+func TestUnits(t *testing.T) {
+	units := Units()
+	assertEqual(t, 13, len(units), "Number of units")
+	for _, u := range units {
+		var scale int64 = 1
+		for i := 0; i < u.Exp; i++ {
+			scale *= int64(u.Base)
+		}
+		assertEqual(t, scale, u.Bytes, "Bytes in %s", u.Symbol)
+
+		size, err := Parse("1" + u.Symbol)
+		if assertNoErr(t, err, "Parsing %s", u.Symbol) {
+			assertEqual(t, u.Bytes, size.Int64(), "Parsed %s", u.Symbol)
+		}
+	}
+
+	// The returned slice is a copy.
+	units[0].Symbol = "x"
+	assertEqual(t, "B", Units()[0].Symbol, "Symbol after modifying result")
+}
+
+func TestLookupUnit(t *testing.T) {
+	tests := []struct {
+		In     string
+		Expect Unit
+		OK     bool
+	}{
+		{In: "B", Expect: ByteUnit, OK: true},
+		{In: "kB", Expect: Kilobyte, OK: true},
+		{In: "k", Expect: Kilobyte, OK: true},
+		{In: "GIB", Expect: Gibibyte, OK: true},
+		{In: "eib", Expect: Exbibyte, OK: true},
+		{In: "byte", Expect: ByteUnit, OK: true},
+		{In: "Bytes", Expect: ByteUnit, OK: true},
+		{In: "megabytes", Expect: Megabyte, OK: true},
+		{In: "Tebibyte", Expect: Tebibyte, OK: true},
+		{In: ""},
+		{In: "Ki"},
+		{In: "kilobytess"},
+	}
+
+	for _, test := range tests {
+		u, ok := LookupUnit(test.In)
+		assertEqual(t, test.OK, ok, "Found %q", test.In)
+		assertEqual(t, test.Expect, u, "Unit for %q", test.In)
+	}
+}
+
+func TestSizeIn(t *testing.T) {
+	assertEqual(t, 1.5, New(1536*MiB, Binary).In(Gibibyte), "1536 MiB in GiB")
+	assertEqual(t, 1.610612736, New(1536*MiB, Binary).In(Gigabyte), "1536 MiB in GB")
+	assertEqual(t, -2048.0, New(-2*MiB, Binary).In(Kibibyte), "-2 MiB in KiB")
+	assertEqual(t, float64(math.MaxInt64), New(math.MaxInt64, Metric).In(ByteUnit), "Max in bytes")
+
+	// Results are rounded to the nearest float64.
+	assertEqual(t, 0.1, New(100, Metric).In(Kilobyte), "100 B in kB")
+	assertEqual(t, 7.999999999999999, New(math.MaxInt64-1023, Binary).In(Exbibyte), "Nearly 8 EiB")
+}
+
+func TestSizeSplit(t *testing.T) {
+	tests := []struct {
+		In        Size
+		Unit      Unit
+		Whole     Size
+		Remainder Size
+	}{
+		{In: *New(3584*MiB, Binary), Unit: Gibibyte, Whole: *New(3*GiB, Binary), Remainder: *New(512*MiB, Binary)},
+		{In: *New(1500, Binary), Unit: Kilobyte, Whole: *New(KB, Metric), Remainder: *New(500, Metric)},
+		{In: *New(-1500, Metric), Unit: Kilobyte, Whole: *New(-KB, Metric), Remainder: *New(-500, Metric)},
+		{In: *New(999, Metric), Unit: Kilobyte, Whole: *New(0, Metric), Remainder: *New(999, Metric)},
+		{In: *New(math.MinInt64, Binary), Unit: Exbibyte, Whole: *New(math.MinInt64, Binary), Remainder: *New(0, Binary)},
+	}
+
+	for _, test := range tests {
+		whole, remainder := test.In.Split(test.Unit)
+		assertEqual(t, test.Whole, whole, "Whole %s in %v", test.Unit, test.In)
+		assertEqual(t, test.Remainder, remainder, "Remainder of %s in %v", test.Unit, test.In)
+	}
+}
+
+func TestInvalidUnit(t *testing.T) {
+	tests := map[string]func(){
+		"In":       func() { New(KB, Metric).In(Unit{}) },
+		"Split":    func() { New(KB, Metric).Split(Unit{}) },
+		"FormatIn": func() { New(KB, Metric).FormatIn(Unit{}) },
+	}
+
+	for name, f := range tests {
+		func() {
+			defer func() {
+				assertEqual(t, `bytefmt: unit "" has 0 bytes; must be positive`, recover(), "%s panic", name)
+			}()
+			f()
+		}()
+	}
+}
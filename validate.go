@@ -0,0 +1,319 @@
+package bytefmt
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+var (
+	sizeType     = reflect.TypeOf(Size{})
+	nullSizeType = reflect.TypeOf(NullSize{})
+)
+
+// Validate checks sizes within a struct against constraints declared in their
+// "bytefmt" struct tags. Tags contain a comma-separated list of rules:
+//
+//    min=<size>      The size must be at least this large.
+//    max=<size>      The size must be no larger than this.
+//    align=<size>    The size must be a multiple of this.
+//    base=<base>     The size must use "metric" or "binary" units.
+//    required        The size must be non-zero, or non-null for a NullSize.
+//
+// For example:
+//
+//    type Config struct {
+//        Cache bytefmt.Size `bytefmt:"min=1MiB,max=10GiB,align=4KiB,required"`
+//    }
+//
+// Tags apply to fields of type Size, NullSize, pointers to either, and slices,
+// arrays or maps of them. Nested structs are validated recursively. Rules other
+// than "required" are skipped for null and nil values.
+//
+// All violations are reported together as a ValidationError. A malformed tag, or
+// one on a field of any other type, produces an ordinary error.
+func Validate(v interface{}) error {
+	w := validator{visiting: map[visit]bool{}}
+	if err := w.walk(reflect.ValueOf(v), "", nil); err != nil {
+		return err
+	}
+	if len(w.errs) != 0 {
+		return w.errs
+	}
+	return nil
+}
+
+// FieldError describes a size which violates a rule.
+type FieldError struct {
+	// Field is the path to the offending value, such as "Cache.Shards[2]".
+	Field string
+
+	// Rule is the violated rule as written in the tag, such as "max=10GiB".
+	Rule string
+
+	Err error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s (%s): %v", e.Field, e.Rule, e.Err)
+}
+
+func (e *FieldError) Unwrap() error { return e.Err }
+
+// ValidationError is the set of all rules violated within a value.
+type ValidationError []*FieldError
+
+func (e ValidationError) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+type validator struct {
+	visiting map[visit]bool // Structs on the path being walked, to detect cycles
+	errs     ValidationError
+}
+
+// visit identifies a struct reached through a pointer.
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+func (w *validator) walk(v reflect.Value, path string, rules []rule) error {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			if v.Type().Elem() == sizeType || v.Type().Elem() == nullSizeType {
+				w.check(NullSize{}, path, rules)
+			}
+			return nil
+		}
+		if v.Elem().Kind() != reflect.Struct || v.Elem().Type() == sizeType || v.Elem().Type() == nullSizeType {
+			return w.walk(v.Elem(), path, rules)
+		}
+
+		// A struct may be reached by several paths, each of which is validated, but
+		// a struct which contains itself is only walked once per path.
+		key := visit{v.Pointer(), v.Type()}
+		if w.visiting[key] {
+			return nil
+		}
+		w.visiting[key] = true
+		defer delete(w.visiting, key)
+		return w.walk(v.Elem(), path, rules)
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return w.walk(v.Elem(), path, rules)
+
+	case reflect.Struct:
+		switch v.Type() {
+		case sizeType:
+			w.check(NullSize{Size: v.Interface().(Size), Valid: true}, path, rules)
+			return nil
+		case nullSizeType:
+			w.check(v.Interface().(NullSize), path, rules)
+			return nil
+		}
+
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.PkgPath != "" {
+				continue // Unexported
+			}
+
+			fieldPath := field.Name
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				fieldPath = ""
+			}
+			fieldPath = joinPath(path, fieldPath)
+
+			fieldRules, err := parseRules(field.Tag.Get("bytefmt"))
+			if err != nil {
+				return fmt.Errorf("invalid bytefmt tag on %s: %w", fieldPath, err)
+			}
+			if len(fieldRules) != 0 && !holdsSizes(field.Type) {
+				return fmt.Errorf("invalid bytefmt tag on %s: unsupported type %s", fieldPath, field.Type)
+			}
+			if err := w.walk(v.Field(i), fieldPath, fieldRules); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			elemPath := fmt.Sprintf("%s[%d]", path, i)
+			if err := w.walk(v.Index(i), elemPath, rules); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.Map:
+		for _, key := range sortedKeys(v) {
+			elemPath := fmt.Sprintf("%s[%v]", path, key)
+			if err := w.walk(v.MapIndex(key), elemPath, rules); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	default:
+		return nil
+	}
+}
+
+// holdsSizes returns whether rules may apply to values of type t: Size,
+// NullSize, or pointers, slices, arrays or maps of them.
+func holdsSizes(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return holdsSizes(t.Elem())
+	default:
+		return t == sizeType || t == nullSizeType
+	}
+}
+
+// sortedKeys returns a map's keys in a deterministic order: numerically for
+// numbers, and by their formatted values otherwise.
+func sortedKeys(m reflect.Value) []reflect.Value {
+	keys := m.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		switch a.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return a.Int() < b.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return a.Uint() < b.Uint()
+		case reflect.Float32, reflect.Float64:
+			return a.Float() < b.Float()
+		case reflect.String:
+			return a.String() < b.String()
+		default:
+			return fmt.Sprint(a) < fmt.Sprint(b)
+		}
+	})
+	return keys
+}
+
+func (w *validator) check(s NullSize, path string, rules []rule) {
+	for _, r := range rules {
+		if err := r.check(s); err != nil {
+			w.errs = append(w.errs, &FieldError{Field: path, Rule: r.text, Err: err})
+		}
+	}
+}
+
+func joinPath(prefix, name string) string {
+	switch {
+	case prefix == "":
+		return name
+	case name == "":
+		return prefix
+	default:
+		return prefix + "." + name
+	}
+}
+
+// rule is a single constraint parsed from a struct tag.
+type rule struct {
+	text  string
+	check func(NullSize) error
+}
+
+func parseRules(tag string) ([]rule, error) {
+	if tag == "" {
+		return nil, nil
+	}
+
+	var rules []rule
+	for _, text := range strings.Split(tag, ",") {
+		text = strings.TrimSpace(text)
+		name, arg := text, ""
+		if i := strings.IndexByte(text, '='); i >= 0 {
+			name, arg = text[:i], text[i+1:]
+		}
+
+		var check func(NullSize) error
+		switch name {
+		case "required":
+			if arg != "" {
+				return nil, fmt.Errorf("%q takes no argument", name)
+			}
+			check = func(s NullSize) error {
+				if !s.Valid || s.Size.IsZero() {
+					return errors.New("is required")
+				}
+				return nil
+			}
+
+		case "min", "max":
+			bound, err := parse(arg)
+			if err != nil {
+				return nil, fmt.Errorf("%q: %w", text, err)
+			}
+			var r Range
+			if name == "min" {
+				r.Min = NullSize{Size: *bound, Valid: true}
+			} else {
+				r.Max = NullSize{Size: *bound, Valid: true}
+			}
+			check = func(s NullSize) error {
+				if !s.Valid {
+					return nil
+				}
+				return r.Validate(s.Size)
+			}
+
+		case "align":
+			align, err := parse(arg)
+			if err != nil {
+				return nil, fmt.Errorf("%q: %w", text, err)
+			}
+			if align.Sign() <= 0 {
+				return nil, fmt.Errorf("%q: alignment must be positive", text)
+			}
+			check = func(s NullSize) error {
+				if !s.Valid || s.Size.bytes%align.bytes == 0 {
+					return nil
+				}
+				return fmt.Errorf("%s is not a multiple of %s", s.Size.String(), align.String())
+			}
+
+		case "base":
+			var base Base
+			switch strings.ToLower(arg) {
+			case "metric":
+				base = Metric
+			case "binary":
+				base = Binary
+			default:
+				return nil, fmt.Errorf("%q: base must be metric or binary", text)
+			}
+			check = func(s NullSize) error {
+				actual := s.Size.Base
+				if actual == 0 {
+					actual = Metric
+				}
+				if !s.Valid || actual == base {
+					return nil
+				}
+				return fmt.Errorf("%s must use %s units", s.Size.String(), strings.ToLower(arg))
+			}
+
+		default:
+			return nil, fmt.Errorf("unknown rule %q", name)
+		}
+		rules = append(rules, rule{text: text, check: check})
+	}
+	return rules, nil
+}
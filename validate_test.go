@@ -0,0 +1,192 @@
+package bytefmt
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	type shard struct {
+		Max Size `bytefmt:"max=1GiB"`
+	}
+
+	type config struct {
+		Cache   Size            `bytefmt:"min=1MiB,max=10GiB,align=4KiB,base=binary,required"`
+		Buffer  NullSize        `bytefmt:"min=1kB"`
+		Spill   *Size           `bytefmt:"required"`
+		Blocks  []Size          `bytefmt:"align=512"`
+		Shards  []shard         // Tags within elements still apply.
+		Nested  *shard          // Nil structs are skipped.
+		Limits  map[string]Size `bytefmt:"max=1kB"`
+		private Size            // Unexported fields are skipped.
+	}
+
+	valid := func() *config {
+		spill := *New(1, Metric)
+		return &config{
+			Cache:  *New(4*MiB, Binary),
+			Spill:  &spill,
+			Blocks: []Size{*New(1024, Binary)},
+			Shards: []shard{{Max: *New(GiB, Binary)}},
+		}
+	}
+
+	tests := []struct {
+		Name   string
+		Modify func(c *config)
+		Expect string
+	}{
+		{Name: "Valid", Modify: func(c *config) {}},
+		{
+			Name:   "Required",
+			Modify: func(c *config) { c.Cache = Size{}; c.Spill = nil },
+			Expect: "Cache (min=1MiB): 0 B is less than the minimum of 1 MiB; " +
+				"Cache (base=binary): 0 B must use binary units; " +
+				"Cache (required): is required; " +
+				"Spill (required): is required",
+		},
+		{
+			Name:   "Bounds",
+			Modify: func(c *config) { c.Cache = *New(11*GiB, Binary) },
+			Expect: "Cache (max=10GiB): 11 GiB is greater than the maximum of 10 GiB",
+		},
+		{
+			Name:   "Alignment",
+			Modify: func(c *config) { c.Cache.Add(*New(512, Binary)) },
+			Expect: "Cache (align=4KiB): 4194816 B is not a multiple of 4 KiB",
+		},
+		{
+			Name:   "Base",
+			Modify: func(c *config) { c.Cache.Base = Metric },
+			Expect: "Cache (base=binary): 4194304 B must use binary units",
+		},
+		{
+			Name:   "NullSize",
+			Modify: func(c *config) { c.Buffer = NullSize{Size: *New(999, Metric), Valid: true} },
+			Expect: "Buffer (min=1kB): 999 B is less than the minimum of 1 kB",
+		},
+		{
+			Name: "Nested",
+			Modify: func(c *config) {
+				c.Blocks = append(c.Blocks, *New(100, Metric))
+				c.Shards = append(c.Shards, shard{Max: *New(2*GiB, Binary)})
+				c.Nested = &shard{Max: *New(3*GiB, Binary)}
+				c.Limits = map[string]Size{
+					"c": *New(4*KB, Metric),
+					"a": *New(2*KB, Metric),
+					"b": *New(KB, Metric),
+					"d": *New(3*KB, Metric),
+				}
+			},
+			Expect: "Blocks[1] (align=512): 100 B is not a multiple of 512 B; " +
+				"Shards[1].Max (max=1GiB): 2 GiB is greater than the maximum of 1 GiB; " +
+				"Nested.Max (max=1GiB): 3 GiB is greater than the maximum of 1 GiB; " +
+				"Limits[a] (max=1kB): 2 kB is greater than the maximum of 1 kB; " +
+				"Limits[c] (max=1kB): 4 kB is greater than the maximum of 1 kB; " +
+				"Limits[d] (max=1kB): 3 kB is greater than the maximum of 1 kB",
+		},
+	}
+
+	for _, test := range tests {
+		c := valid()
+		test.Modify(c)
+		err := Validate(c)
+		if test.Expect == "" {
+			assertNoErr(t, err, "%s: validating", test.Name)
+			continue
+		}
+
+		assertEqualErr(t, test.Expect, err, "%s: validating", test.Name)
+		var verr ValidationError
+		assertEqual(t, true, errors.As(err, &verr), "%s: error type", test.Name)
+	}
+}
+
+func TestValidatePointers(t *testing.T) {
+	type node struct {
+		Size Size `bytefmt:"max=1kB"`
+		Next *node
+	}
+
+	// A size shared by several fields is checked against each field's rules.
+	size := *New(2*KB, Metric)
+	shared := struct {
+		A *Size `bytefmt:"max=10kB"`
+		B *Size `bytefmt:"max=1kB"`
+	}{&size, &size}
+	assertEqualErr(t, "B (max=1kB): 2 kB is greater than the maximum of 1 kB", Validate(shared),
+		"Validating shared size")
+
+	// Cycles are walked once, but a struct reached by several paths is checked on each.
+	n := &node{Size: *New(2*KB, Metric)}
+	n.Next = n
+	assertEqualErr(t, "Size (max=1kB): 2 kB is greater than the maximum of 1 kB", Validate(n),
+		"Validating cycle")
+
+	pair := struct{ X, Y *node }{n, n}
+	assertEqualErr(t, "X.Size (max=1kB): 2 kB is greater than the maximum of 1 kB; "+
+		"Y.Size (max=1kB): 2 kB is greater than the maximum of 1 kB", Validate(pair),
+		"Validating shared struct")
+}
+
+func TestValidateInvalidTag(t *testing.T) {
+	tests := []struct {
+		In     interface{}
+		Expect string
+	}{
+		{
+			In: struct {
+				A Size `bytefmt:"minimum=1"`
+			}{},
+			Expect: `invalid bytefmt tag on A: unknown rule "minimum"`,
+		},
+		{
+			In: struct {
+				B Size `bytefmt:"max=lots"`
+			}{},
+			Expect: `invalid bytefmt tag on B: "max=lots": must start with a number`,
+		},
+		{
+			In: struct {
+				C Size `bytefmt:"align=0"`
+			}{},
+			Expect: `invalid bytefmt tag on C: "align=0": alignment must be positive`,
+		},
+		{
+			In: struct {
+				D Size `bytefmt:"base=decimal"`
+			}{},
+			Expect: `invalid bytefmt tag on D: "base=decimal": base must be metric or binary`,
+		},
+		{
+			In: struct {
+				N int `bytefmt:"min=1MiB"`
+			}{},
+			Expect: `invalid bytefmt tag on N: unsupported type int`,
+		},
+		{
+			In: struct {
+				Lim Limit `bytefmt:"max=1kB"`
+			}{Lim: NewLimit(*New(5*KB, Metric))},
+			Expect: `invalid bytefmt tag on Lim: unsupported type bytefmt.Limit`,
+		},
+		{
+			In: struct {
+				Outer struct {
+					In struct{ S Size } `bytefmt:"required"`
+				}
+			}{},
+			Expect: `invalid bytefmt tag on Outer.In: unsupported type struct { S bytefmt.Size }`,
+		},
+		{
+			In: struct {
+				M map[string][]int `bytefmt:"max=1kB"`
+			}{},
+			Expect: `invalid bytefmt tag on M: unsupported type map[string][]int`,
+		},
+	}
+
+	for _, test := range tests {
+		assertEqualErr(t, test.Expect, Validate(test.In), "Validating %T", test.In)
+	}
+}
@@ -0,0 +1,68 @@
+package bytefmt
+
+import "fmt"
+
+// The YAML methods below use the signatures from gopkg.in/yaml.v2, which
+// gopkg.in/yaml.v3 also supports, so the package needn't depend on either.
+
+// MarshalYAML implements the yaml.Marshaler interface.
+func (s Size) MarshalYAML() (interface{}, error) {
+	return s.String(), nil
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface. It accepts strings
+// and numbers, which are interpreted as a count of bytes.
+func (s *Size) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var value interface{}
+	if err := unmarshal(&value); err != nil {
+		return err
+	}
+	return s.decode(value, "YAML")
+}
+
+// MarshalYAML implements the yaml.Marshaler interface.
+func (s NullSize) MarshalYAML() (interface{}, error) {
+	if !s.Valid {
+		return nil, nil
+	}
+	return s.Size.MarshalYAML()
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface. It accepts strings,
+// numbers, and null. An empty string also sets the size to null.
+func (s *NullSize) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var value interface{}
+	if err := unmarshal(&value); err != nil {
+		return err
+	}
+	switch v := value.(type) {
+	case nil:
+		*s = NullSize{}
+		return nil
+	case string:
+		return s.Set(v)
+	}
+	if err := s.Size.decode(value, "YAML"); err != nil {
+		return err
+	}
+	s.Valid = true
+	return nil
+}
+
+// decode sets a size from a string or number produced by a generic decoder.
+func (s *Size) decode(value interface{}, format string) error {
+	if str, ok := value.(string); ok {
+		return s.Set(str)
+	}
+
+	bytes, ok, err := fromNumber(value)
+	switch {
+	case !ok:
+		return fmt.Errorf("can't decode %s value '%+v' of type '%T' as bytefmt.Size",
+			format, value, value)
+	case err != nil:
+		return fmt.Errorf("can't decode %s value %v as bytefmt.Size: %w", format, value, err)
+	}
+	*s = *New(bytes, Metric)
+	return nil
+}
@@ -0,0 +1,89 @@
+package bytefmt
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// fakeNode stands in for a decoded YAML node. Its unmarshal method mimics the
+// callback passed to UnmarshalYAML by yaml.v2 and yaml.v3.
+type fakeNode struct {
+	value interface{}
+	err   error
+}
+
+func (n fakeNode) unmarshal(out interface{}) error {
+	if n.err != nil {
+		return n.err
+	}
+	v := reflect.ValueOf(out).Elem()
+	if n.value == nil {
+		v.Set(reflect.Zero(v.Type()))
+	} else {
+		v.Set(reflect.ValueOf(n.value))
+	}
+	return nil
+}
+
+func TestUnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		In        fakeNode
+		Expect    NullSize
+		ExpectErr string
+	}{
+		// Strings are parsed.
+		{In: fakeNode{value: "1.5 GiB"}, Expect: NullSize{Size: *New(1536*MiB, Binary), Valid: true}},
+		{In: fakeNode{value: "1024"}, Expect: NullSize{Size: *New(1024, Metric), Valid: true}},
+
+		// Numbers are bytes.
+		{In: fakeNode{value: 1048576}, Expect: NullSize{Size: *New(MiB, Metric), Valid: true}},
+		{In: fakeNode{value: uint64(math.MaxInt64)}, Expect: NullSize{Size: *New(math.MaxInt64, Metric), Valid: true}},
+		{In: fakeNode{value: 1e9}, Expect: NullSize{Size: *New(GB, Metric), Valid: true}},
+
+		// Invalid values
+		{In: fakeNode{value: "lots"}, ExpectErr: `can't convert "lots" to size: must start with a number`},
+		{In: fakeNode{value: 1.5}, ExpectErr: "can't decode YAML value 1.5 as bytefmt.Size: 1.5 is not a whole number of bytes"},
+		{In: fakeNode{value: 1e19}, ExpectErr: "can't decode YAML value 1e+19 as bytefmt.Size: value exceeds 64 bits"},
+		{In: fakeNode{value: uint64(math.MaxInt64 + 1)}, ExpectErr: "can't decode YAML value 9223372036854775808 as bytefmt.Size: value exceeds 64 bits"},
+		{In: fakeNode{value: math.Inf(1)}, ExpectErr: "can't decode YAML value +Inf as bytefmt.Size: +Inf is not a number of bytes"},
+		{In: fakeNode{value: true}, ExpectErr: "can't decode YAML value 'true' of type 'bool' as bytefmt.Size"},
+		{In: fakeNode{err: errors.New("bad node")}, ExpectErr: "bad node"},
+	}
+
+	for _, test := range tests {
+		var size Size
+		err := size.UnmarshalYAML(test.In.unmarshal)
+
+		if test.ExpectErr != "" {
+			assertEqualErr(t, test.ExpectErr, err, "Error for %v", test.In.value)
+			continue
+		}
+		if assertNoErr(t, err, "Unexpected error for %v", test.In.value) {
+			assertEqual(t, test.Expect.Size, size, "Size for %v", test.In.value)
+		}
+
+		var null NullSize
+		if assertNoErr(t, null.UnmarshalYAML(test.In.unmarshal), "Unexpected error for %v", test.In.value) {
+			assertEqual(t, test.Expect, null, "NullSize for %v", test.In.value)
+		}
+	}
+}
+
+func TestNullYAML(t *testing.T) {
+	for _, node := range []fakeNode{{value: nil}, {value: ""}} {
+		null := NullSize{Size: *New(1, Metric), Valid: true}
+		if assertNoErr(t, null.UnmarshalYAML(node.unmarshal), "Unmarshalling %q", node.value) {
+			assertEqual(t, NullSize{}, null, "NullSize for %q", node.value)
+		}
+	}
+
+	value, err := NullSize{}.MarshalYAML()
+	assertNoErr(t, err, "Marshalling null")
+	assertEqual(t, nil, value, "Marshalled null")
+
+	value, err = NullSize{Size: *New(4*GiB, Binary), Valid: true}.MarshalYAML()
+	assertNoErr(t, err, "Marshalling value")
+	assertEqual(t, "4 GiB", value, "Marshalled value")
+}